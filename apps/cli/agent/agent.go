@@ -0,0 +1,198 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/deploy"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/logship"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// maxBackoff caps the wait between polls after consecutive server errors.
+const maxBackoff = time.Minute
+
+// Config controls a Tendril agent.
+type Config struct {
+	// Origin is the portal URL, e.g. https://beta.idp.itgix.com. The agent
+	// claims jobs from /api/cli/deployments/claim, reports status with
+	// PUT /api/cli/deployments/{id} and writes logs to
+	// /api/cli/deployments/{id}/logs.
+	Origin string
+	// Version is the CLI version reported in the User-Agent header.
+	Version string
+	// Token is the machine token the agent authenticates with, an API token
+	// with the configs:write scope.
+	Token string
+	// PollInterval is the wait between polls when the queue is empty.
+	PollInterval time.Duration
+	// WorkDir is the parent directory for per-job working directories.
+	WorkDir string
+	// Deploy holds the options passed to every deployment. WorkDir and DryRun
+	// are set per job.
+	Deploy deploy.Options
+	// Logf reports agent activity. Defaults to log.Printf.
+	Logf func(format string, args ...any)
+	// Reveal keeps tokens in the shipped logs and in Logf output instead of
+	// masking them.
+	Reveal bool
+}
+
+// Agent polls the portal for queued deployments and executes them one at a time.
+type Agent struct {
+	cfg    Config
+	client *api.Client
+}
+
+func New(cfg Config) *Agent {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 10 * time.Second
+	}
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = filepath.Join(os.TempDir(), "grape-agent")
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = log.Printf
+	}
+	if !cfg.Reveal {
+		logf := cfg.Logf
+		cfg.Logf = func(format string, args ...any) {
+			logf("%s", redact.String(fmt.Sprintf(format, args...)))
+		}
+	}
+	client := api.New(api.Config{
+		Origin:  cfg.Origin,
+		Version: cfg.Version,
+		Tokens:  api.StaticToken(cfg.Token),
+	})
+
+	return &Agent{cfg: cfg, client: client}
+}
+
+// Run polls for jobs until ctx is cancelled. A job that is running when ctx is
+// cancelled is stopped and reported as failed.
+func (a *Agent) Run(ctx context.Context) error {
+	a.cfg.Logf("Agent started, polling %s every %s", a.client.Origin(), a.cfg.PollInterval)
+
+	wait := a.cfg.PollInterval
+	for {
+		found, err := a.RunOnce(ctx)
+		switch {
+		case ctx.Err() != nil:
+			a.cfg.Logf("Agent stopped")
+			return nil
+		case err != nil:
+			a.cfg.Logf("Error: %v (retrying in %s)", err, wait)
+		case found:
+			// Check for the next job straight away.
+			wait = a.cfg.PollInterval
+			continue
+		default:
+			wait = a.cfg.PollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			a.cfg.Logf("Agent stopped")
+			return nil
+		case <-time.After(wait):
+		}
+
+		if err != nil {
+			wait = min(wait*2, maxBackoff)
+		}
+	}
+}
+
+// RunOnce claims and executes a single job. It reports whether a job was found.
+func (a *Agent) RunOnce(ctx context.Context) (bool, error) {
+	job, err := a.client.ClaimDeployment(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error claiming job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	a.cfg.Logf("Claimed deployment %s (%s)", job.ID, job.Name)
+	runErr := a.execute(ctx, job)
+
+	// Report with a fresh context so a cancelled job is still marked as failed.
+	reportCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if runErr != nil {
+		a.cfg.Logf("Deployment %s failed: %v", job.ID, runErr)
+		return true, a.report(reportCtx, job.ID, types.DeploymentFailed, runErr.Error())
+	}
+	a.cfg.Logf("Deployment %s succeeded", job.ID)
+	return true, a.report(reportCtx, job.ID, types.DeploymentSuccess, "")
+}
+
+func (a *Agent) execute(ctx context.Context, job *types.Deployment) error {
+	if job.ConfigSnapshot == nil {
+		return errors.New("deployment has no config_snapshot")
+	}
+
+	opts := a.cfg.Deploy
+	opts.WorkDir = filepath.Join(a.cfg.WorkDir, job.ID)
+	opts.DryRun = false
+	defer os.RemoveAll(opts.WorkDir)
+
+	spool, err := logship.SpoolPath(job.ID)
+	if err != nil {
+		spool = ""
+	}
+	shipper := logship.New(logship.Config{
+		URL:       a.client.DeploymentLogsURL(job.ID),
+		Tokens:    api.StaticToken(a.cfg.Token),
+		SpoolFile: spool,
+		Reveal:    a.cfg.Reveal,
+		Version:   a.cfg.Version,
+		Logf: func(format string, args ...any) {
+			a.cfg.Logf("[%s] "+format, append([]any{job.ID}, args...)...)
+		},
+	})
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := shipper.Close(closeCtx); err != nil {
+			a.cfg.Logf("[%s] %v", job.ID, err)
+		}
+	}()
+
+	config := *job.ConfigSnapshot
+	for _, step := range deploy.Steps(&config, opts) {
+		a.cfg.Logf("[%s] %s", job.ID, step.Name)
+		shipper.Log(logship.LevelInfo, step.Name, "Starting "+step.Name)
+
+		out := shipper.Step(step.Name)
+		err := step.Run(ctx, out)
+		out.Close()
+
+		if ctx.Err() != nil {
+			err = fmt.Errorf("cancelled during %q", step.Name)
+			shipper.Log(logship.LevelError, step.Name, err.Error())
+			return err
+		}
+		if err != nil {
+			shipper.Log(logship.LevelError, step.Name, err.Error())
+			return fmt.Errorf("%s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Agent) report(ctx context.Context, id, status, message string) error {
+	if err := a.client.ReportDeployment(ctx, id, status, message); err != nil {
+		return fmt.Errorf("error reporting status: %w", err)
+	}
+	return nil
+}
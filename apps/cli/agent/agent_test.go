@@ -0,0 +1,273 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/logship"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const testToken = "grape_test"
+
+// portal is a stand-in for the agent routes of the portal. It hands out
+// the queued jobs in order and records logs and reports.
+type portal struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	queue   []types.Deployment
+	logs    map[string][]logship.Entry
+	reports map[string]map[string]string
+	// failReports is how many reports are answered with 503 before one is
+	// accepted.
+	failReports int
+}
+
+func newPortal(t *testing.T, jobs ...types.Deployment) (*portal, *httptest.Server) {
+	p := &portal{
+		t:       t,
+		queue:   jobs,
+		logs:    map[string][]logship.Entry{},
+		reports: map[string]map[string]string{},
+	}
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	return p, srv
+}
+
+func (p *portal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if ua := r.Header.Get("User-Agent"); !strings.HasPrefix(ua, "grape-cli/") {
+		p.t.Errorf("User-Agent = %q, want the CLI user agent", ua)
+	}
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized: Invalid token"})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/cli/deployments/")
+	id, rest, _ := strings.Cut(path, "/")
+	switch {
+	case r.Method == http.MethodPost && path == "claim":
+		if len(p.queue) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		job := p.queue[0]
+		p.queue = p.queue[1:]
+		job.Status = types.DeploymentProcessing
+		json.NewEncoder(w).Encode(map[string]any{"deployment": job})
+	case r.Method == http.MethodPost && rest == "logs":
+		var entries []logship.Entry
+		if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+			p.t.Errorf("decoding logs: %v", err)
+		}
+		p.logs[id] = append(p.logs[id], entries...)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && rest == "" && p.failReports > 0:
+		p.failReports--
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case r.Method == http.MethodPut && rest == "":
+		var report map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			p.t.Errorf("decoding report: %v", err)
+		}
+		p.reports[id] = report
+		json.NewEncoder(w).Encode(map[string]any{"deployment": map[string]string{"id": id}})
+	default:
+		p.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (p *portal) report(id string) map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reports[id]
+}
+
+func (p *portal) messages(id string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var b strings.Builder
+	for _, e := range p.logs[id] {
+		b.WriteString(e.Message + "\n")
+	}
+	return b.String()
+}
+
+// templateRepo creates a git repository to clone the template from.
+func templateRepo(t *testing.T) string {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("main.tf"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	if _, err := wt.Commit("template", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// fakeTerraform writes a terraform 1.5.0 stand-in that prints its
+// subcommand and fails on failOn.
+func fakeTerraform(t *testing.T, failOn string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the terraform stand-in is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "terraform")
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = version ]; then echo '{\"terraform_version\":\"1.5.0\"}'; exit 0; fi\n" +
+		"echo \"fake terraform $1\"\n"
+	if failOn != "" {
+		script += "if [ \"$1\" = " + failOn + " ]; then echo \"Error: $1 failed\" >&2; exit 1; fi\n"
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testConfig(t *testing.T, origin, terraform string) Config {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cfg := Config{
+		Origin:  origin,
+		Token:   testToken,
+		WorkDir: t.TempDir(),
+		Logf:    t.Logf,
+	}
+	cfg.Deploy.TerraformBin = terraform
+	return cfg
+}
+
+func testJob(id, repo string) types.Deployment {
+	return types.Deployment{
+		ID:     id,
+		Name:   "test-" + id,
+		Status: types.DeploymentQueued,
+		ConfigSnapshot: &types.Configuration{
+			ProjectName:      "test",
+			EnvironmentStage: "dev",
+			AwsRegion:        "eu-central-1",
+			AwsAccountID:     "123456789012",
+			EnvTemplateRepo:  repo,
+			TerraformVersion: "1.5.0",
+		},
+	}
+}
+
+func TestRunOnceSuccess(t *testing.T) {
+	p, srv := newPortal(t, testJob("job-1", templateRepo(t)))
+	a := New(testConfig(t, srv.URL, fakeTerraform(t, "")))
+
+	found, err := a.RunOnce(context.Background())
+	if err != nil || !found {
+		t.Fatalf("RunOnce() = %v, %v, want true, nil", found, err)
+	}
+
+	if got := p.report("job-1"); got["status"] != types.DeploymentSuccess || got["error_message"] != "" {
+		t.Errorf("report = %v, want status %s without error_message", got, types.DeploymentSuccess)
+	}
+	logs := p.messages("job-1")
+	for _, want := range []string{"Starting Clone template repository", "fake terraform init", "fake terraform plan", "fake terraform apply"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs lack %q:\n%s", want, logs)
+		}
+	}
+
+	found, err = a.RunOnce(context.Background())
+	if err != nil || found {
+		t.Errorf("RunOnce() on an empty queue = %v, %v, want false, nil", found, err)
+	}
+}
+
+func TestRunOnceFailure(t *testing.T) {
+	p, srv := newPortal(t, testJob("job-2", templateRepo(t)))
+	a := New(testConfig(t, srv.URL, fakeTerraform(t, "apply")))
+
+	found, err := a.RunOnce(context.Background())
+	if err != nil || !found {
+		t.Fatalf("RunOnce() = %v, %v, want true, nil", found, err)
+	}
+
+	got := p.report("job-2")
+	if got["status"] != types.DeploymentFailed || !strings.Contains(got["error_message"], "terraform apply failed") {
+		t.Errorf("report = %v, want %s with the terraform error", got, types.DeploymentFailed)
+	}
+	if logs := p.messages("job-2"); !strings.Contains(logs, "Error: apply failed") {
+		t.Errorf("logs lack the terraform error:\n%s", logs)
+	}
+}
+
+func TestRunOnceTerraformVersionMismatch(t *testing.T) {
+	job := testJob("job-4", templateRepo(t))
+	job.ConfigSnapshot.TerraformVersion = "1.4.6"
+	p, srv := newPortal(t, job)
+	a := New(testConfig(t, srv.URL, fakeTerraform(t, "")))
+
+	if _, err := a.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := p.report("job-4")
+	if got["status"] != types.DeploymentFailed || !strings.Contains(got["error_message"], "requires Terraform 1.4.6") {
+		t.Errorf("report = %v, want %s for the version mismatch", got, types.DeploymentFailed)
+	}
+	if logs := p.messages("job-4"); strings.Contains(logs, "fake terraform init") {
+		t.Errorf("terraform ran despite the version mismatch:\n%s", logs)
+	}
+}
+
+func TestRunOnceUnauthorized(t *testing.T) {
+	_, srv := newPortal(t, testJob("job-3", ""))
+	cfg := testConfig(t, srv.URL, "")
+	cfg.Token = "wrong"
+	a := New(cfg)
+
+	found, err := a.RunOnce(context.Background())
+	if found || !errors.Is(err, api.ErrUnauthorized) {
+		t.Errorf("RunOnce() = %v, %v, want false and ErrUnauthorized", found, err)
+	}
+}
+
+func TestRunOnceRetriesReport(t *testing.T) {
+	p, srv := newPortal(t, testJob("job-5", templateRepo(t)))
+	p.failReports = 1
+	a := New(testConfig(t, srv.URL, fakeTerraform(t, "")))
+
+	found, err := a.RunOnce(context.Background())
+	if err != nil || !found {
+		t.Fatalf("RunOnce() = %v, %v, want true, nil", found, err)
+	}
+	if got := p.report("job-5"); got["status"] != types.DeploymentSuccess {
+		t.Errorf("report = %v, want status %s after a retry", got, types.DeploymentSuccess)
+	}
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/imroc/req/v3"
+)
+
+const (
+	defaultTimeout  = 30 * time.Second
+	defaultRetries  = 2
+	retryMinBackoff = 250 * time.Millisecond
+	retryMaxBackoff = 5 * time.Second
+)
+
+// TokenSource supplies the bearer token for authenticated requests.
+type TokenSource interface {
+	// Token returns a usable access token, refreshing it first when it is
+	// about to expire.
+	Token(ctx context.Context) (string, error)
+	// Refresh obtains a new access token after the server rejected the
+	// rejected one, which was returned by Token.
+	Refresh(ctx context.Context, rejected string) (string, error)
+}
+
+// StaticToken is a TokenSource for a token that cannot be refreshed, such as
+// an API token or the machine token of an agent.
+type StaticToken string
+
+func (t StaticToken) Token(ctx context.Context) (string, error) { return string(t), nil }
+
+func (t StaticToken) Refresh(ctx context.Context, rejected string) (string, error) {
+	return "", ErrUnauthorized
+}
+
+// Config controls a Client.
+type Config struct {
+	// Origin is the portal URL, e.g. https://beta.idp.itgix.com.
+	Origin string
+	// Tokens authenticates requests. Only needed for authenticated endpoints.
+	Tokens TokenSource
+	// Version is the CLI version reported in the User-Agent header.
+	Version string
+	// Timeout bounds each attempt. Defaults to 30s.
+	Timeout time.Duration
+	// Retries is how many times a request is repeated after a network error,
+	// 429 or 5xx response. Only idempotent requests are repeated. Defaults
+	// to 2; negative disables retries.
+	Retries int
+}
+
+// Client talks to the portal's CLI endpoints.
+type Client struct {
+	origin string
+	tokens TokenSource
+	http   *req.Client
+	// stream is http without the timeout and retries, for event streams.
+	stream *req.Client
+}
+
+// New returns a client for cfg.Origin.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = defaultRetries
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+
+	hc := req.C().
+		SetTimeout(cfg.Timeout).
+		SetUserAgent(UserAgent(cfg.Version)).
+		SetCommonRetryCount(cfg.Retries).
+		SetCommonRetryBackoffInterval(retryMinBackoff, retryMaxBackoff).
+		SetCommonRetryCondition(shouldRetry)
+
+	return &Client{
+		origin: strings.TrimRight(cfg.Origin, "/"),
+		tokens: cfg.Tokens,
+		http:   hc,
+		stream: hc.Clone().SetTimeout(0).SetCommonRetryCount(0),
+	}
+}
+
+// UserAgent returns the User-Agent header sent by the CLI.
+func UserAgent(version string) string {
+	if version == "" {
+		version = "dev"
+	}
+	return fmt.Sprintf("grape-cli/%s (%s; %s)", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// Origin returns the portal URL the client talks to.
+func (c *Client) Origin() string {
+	return c.origin
+}
+
+func shouldRetry(resp *req.Response, err error) bool {
+	if err != nil {
+		// Don't retry when the caller gave up.
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// rejectedError is returned when the server rejected the token and it could
+// not be refreshed. It reads as the refresh error and matches
+// ErrUnauthorized, so callers can tell it from a network error.
+type rejectedError struct{ err error }
+
+func (e *rejectedError) Error() string   { return e.err.Error() }
+func (e *rejectedError) Unwrap() []error { return []error{e.err, ErrUnauthorized} }
+
+// request describes a single API call.
+type request struct {
+	method string
+	path   string
+	body   any
+	auth   bool
+	// idempotent marks a POST or PATCH that may be sent twice, such as a
+	// revocation.
+	idempotent bool
+}
+
+// retryable reports whether r may be repeated after a network error or a
+// server error. A POST that creates something or rotates a token is not,
+// since the first attempt may have reached the server.
+func (r request) retryable() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return r.idempotent
+}
+
+// do sends r and decodes a successful response into out. Authenticated
+// requests that come back 401 are repeated once with a refreshed token.
+func (c *Client) do(ctx context.Context, r request, out any) error {
+	var token string
+	if r.auth {
+		if c.tokens == nil {
+			return ErrNotLoggedIn
+		}
+		var err error
+		if token, err = c.tokens.Token(ctx); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.send(ctx, r, token)
+	if err != nil {
+		return err
+	}
+
+	if r.auth && resp.StatusCode == http.StatusUnauthorized {
+		if token, err = c.tokens.Refresh(ctx, token); err != nil {
+			return &rejectedError{err: err}
+		}
+		if resp, err = c.send(ctx, r, token); err != nil {
+			return err
+		}
+	}
+
+	body, err := resp.ToBytes()
+	if err != nil {
+		return fmt.Errorf("error reading response from %s: %w", c.origin, err)
+	}
+	if resp.IsErrorState() {
+		return newError(resp.StatusCode, body)
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error decoding response from %s%s: %w", c.origin, r.path, err)
+	}
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, r request, token string) (*req.Response, error) {
+	rq := c.http.R().SetContext(ctx)
+	if !r.retryable() {
+		rq.SetRetryCount(0)
+	}
+	if token != "" {
+		rq.SetBearerAuthToken(token)
+	}
+	if r.body != nil {
+		rq.SetBody(r.body)
+	}
+
+	resp, err := rq.Send(r.method, c.origin+r.path)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", c.origin, err)
+	}
+	return resp, nil
+}
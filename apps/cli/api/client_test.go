@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// testTokens hands out "old" and refreshes it to "new", or fails with err.
+type testTokens struct {
+	err       error
+	refreshed []string
+}
+
+func (t *testTokens) Token(ctx context.Context) (string, error) { return "old", nil }
+
+func (t *testTokens) Refresh(ctx context.Context, rejected string) (string, error) {
+	t.refreshed = append(t.refreshed, rejected)
+	if t.err != nil {
+		return "", t.err
+	}
+	return "new", nil
+}
+
+// flakyServer fails the first failures requests with 503 and answers the
+// rest with body.
+func flakyServer(t *testing.T, failures int32, body string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRetriesIdempotentRequests(t *testing.T) {
+	srv, calls := flakyServer(t, 2, `{"user_id":"u1","token_type":"login"}`)
+	c := New(Config{Origin: srv.URL, Tokens: StaticToken("token")})
+
+	info, err := c.WhoAmI(context.Background())
+	if err != nil {
+		t.Fatalf("WhoAmI() error = %v", err)
+	}
+	if info.UserID != "u1" {
+		t.Errorf("UserID = %q, want u1", info.UserID)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3", got)
+	}
+}
+
+func TestRetriesRequestsMarkedIdempotent(t *testing.T) {
+	srv, calls := flakyServer(t, 1, "")
+	c := New(Config{Origin: srv.URL})
+
+	if err := c.RevokeSession(context.Background(), "refresh"); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestDoesNotRetryPost(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+	}{
+		{"CreateConfiguration", func(c *Client) error {
+			_, err := c.CreateConfiguration(context.Background(), types.Configuration{ProjectName: "p"})
+			return err
+		}},
+		{"Refresh", func(c *Client) error {
+			_, _, err := c.Refresh(context.Background(), "refresh")
+			return err
+		}},
+		{"Exchange", func(c *Client) error {
+			_, err := c.Exchange(context.Background(), "device", "code")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := flakyServer(t, 1, "{}")
+			c := New(Config{Origin: srv.URL, Tokens: StaticToken("token")})
+
+			var apiErr *Error
+			if err := tt.call(c); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("error = %v, want HTTP 503", err)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("server saw %d requests, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRefreshesRejectedToken(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		if auth != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"Unauthorized: Token expired"}`)
+			return
+		}
+		fmt.Fprint(w, `{"user_id":"u1"}`)
+	}))
+	defer srv.Close()
+
+	tokens := &testTokens{}
+	c := New(Config{Origin: srv.URL, Tokens: tokens})
+	if _, err := c.WhoAmI(context.Background()); err != nil {
+		t.Fatalf("WhoAmI() error = %v", err)
+	}
+	if len(tokens.refreshed) != 1 || tokens.refreshed[0] != "old" {
+		t.Errorf("refreshed %q, want [old]", tokens.refreshed)
+	}
+	if len(seen) != 2 || seen[0] != "Bearer old" || seen[1] != "Bearer new" {
+		t.Errorf("server saw %q, want [Bearer old, Bearer new]", seen)
+	}
+}
+
+func TestRefreshFailure(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	refreshErr := errors.New("session revoked")
+	c := New(Config{Origin: srv.URL, Tokens: &testTokens{err: refreshErr}})
+
+	_, err := c.WhoAmI(context.Background())
+	if !errors.Is(err, refreshErr) || !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("error = %v, want the refresh error matching ErrUnauthorized", err)
+	}
+	if err.Error() != refreshErr.Error() {
+		t.Errorf("error reads %q, want %q", err, refreshErr)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestNotLoggedIn(t *testing.T) {
+	c := New(Config{Origin: "http://127.0.0.1:0"})
+	if _, err := c.WhoAmI(context.Background()); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("error = %v, want ErrNotLoggedIn", err)
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		sentinel    error
+		message     string
+		description string
+		text        string
+	}{
+		{
+			name:     "json error",
+			status:   http.StatusNotFound,
+			body:     `{"error":"Configuration not found"}`,
+			sentinel: ErrNotFound,
+			message:  "Configuration not found",
+			text:     "HTTP 404: Configuration not found",
+		},
+		{
+			name:        "oauth error",
+			status:      http.StatusBadRequest,
+			body:        `{"error":"invalid_grant","error_description":"The device code expired"}`,
+			message:     "invalid_grant",
+			description: "The device code expired",
+			text:        "HTTP 400: invalid_grant",
+		},
+		{
+			name:     "conflict",
+			status:   http.StatusConflict,
+			body:     `{"error":"Configuration changed"}`,
+			sentinel: ErrConflict,
+			message:  "Configuration changed",
+			text:     "HTTP 409: Configuration changed",
+		},
+		{
+			name:     "plain text",
+			status:   http.StatusForbidden,
+			body:     "Access denied\n",
+			sentinel: ErrForbidden,
+			message:  "Access denied",
+			text:     "HTTP 403: Access denied",
+		},
+		{
+			name:   "empty body",
+			status: http.StatusBadGateway,
+			text:   "HTTP 502 Bad Gateway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			c := New(Config{Origin: srv.URL, Tokens: StaticToken("token"), Retries: -1})
+			_, err := c.WhoAmI(context.Background())
+
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *Error", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.message || apiErr.Description != tt.description {
+				t.Errorf("error = %+v, want status %d, message %q, description %q", apiErr, tt.status, tt.message, tt.description)
+			}
+			if err.Error() != tt.text {
+				t.Errorf("error reads %q, want %q", err, tt.text)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("error does not match %v", tt.sentinel)
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// Errors of the device flow (RFC 8628 §3.5), returned by Exchange.
+var (
+	// ErrAuthorizationPending means the user has not yet approved the login
+	// in the browser.
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// ErrSlowDown means the poll interval must grow by 5 seconds.
+	ErrSlowDown = errors.New("polling too fast")
+	// ErrExpiredToken means the device code expired before it was approved.
+	ErrExpiredToken = errors.New("the login code expired")
+	// ErrAccessDenied means the user denied the login.
+	ErrAccessDenied = errors.New("the login was denied in the browser")
+
+	// ErrVerificationRequired means the login was approved, and Exchange
+	// must be called again with the verification code shown in the browser.
+	ErrVerificationRequired = errors.New("verification code required")
+	// ErrInvalidVerificationCode means the verification code was wrong. The
+	// returned error says how many attempts are left.
+	ErrInvalidVerificationCode = errors.New("incorrect verification code")
+	// ErrTooManyAttempts means too many wrong verification codes were sent,
+	// and the login was cancelled.
+	ErrTooManyAttempts = errors.New("too many incorrect verification codes")
+)
+
+// describedError is a sentinel error with the server's description of it.
+type describedError struct {
+	err         error
+	description string
+}
+
+func (e *describedError) Error() string { return e.description }
+func (e *describedError) Unwrap() error { return e.err }
+
+// deviceCodeGrantType is the grant_type of Exchange.
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceAuthorization starts a device flow login.
+type DeviceAuthorization struct {
+	DeviceCode string `json:"device_code"`
+	// UserCode is entered, or confirmed, by the user at VerificationURI.
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	// VerificationURIComplete includes the user code.
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// ExpiresIn and Interval are in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// ListOptions filters, sorts and paginates ListConfigurations. The zero
+// value returns every configuration sorted by project name.
+type ListOptions struct {
+	Stage        string
+	Platform     string
+	UpdatedSince time.Time
+	// Search matches part of the project name, description, stage or platform.
+	Search string
+	// Sort is the field to sort by: project_name, environment_stage,
+	// container_platform, created_at or updated_at.
+	Sort       string
+	Descending bool
+	// Limit is the page size; 0 returns all matches. Page starts at 1.
+	Limit int
+	Page  int
+}
+
+func (o ListOptions) query() url.Values {
+	q := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	set("stage", o.Stage)
+	set("platform", o.Platform)
+	set("search", o.Search)
+	set("sort", o.Sort)
+	if o.Descending {
+		q.Set("order", "desc")
+	}
+	if !o.UpdatedSince.IsZero() {
+		q.Set("updated_since", o.UpdatedSince.UTC().Format(time.RFC3339))
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Page > 1 {
+		q.Set("page", strconv.Itoa(o.Page))
+	}
+	return q
+}
+
+// ConfigurationPage is one page of ListConfigurations.
+type ConfigurationPage struct {
+	Configurations []types.ConfigurationSummary `json:"configurations"`
+	// Total is the number of configurations matching the filters.
+	Total int `json:"total"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+// ListConfigurations returns the configurations of the logged-in user.
+func (c *Client) ListConfigurations(ctx context.Context, opts ListOptions) (*ConfigurationPage, error) {
+	path := "/api/cli/configurations"
+	if q := opts.query(); len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var result ConfigurationPage
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, auth: true}, &result); err != nil {
+		return nil, err
+	}
+	if result.Total < len(result.Configurations) {
+		// Older servers don't paginate.
+		result.Total = len(result.Configurations)
+	}
+	return &result, nil
+}
+
+// GetConfigurationByProjectName returns a single configuration.
+func (c *Client) GetConfigurationByProjectName(ctx context.Context, projectName string) (*types.Configuration, error) {
+	var result struct {
+		Configuration types.Configuration `json:"configuration"`
+	}
+	path := "/api/cli/configurations/by-project-name/" + url.PathEscape(projectName)
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, auth: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result.Configuration, nil
+}
+
+// CreateConfiguration stores a new configuration. The server answers 409,
+// matched by ErrConflict, when the project name is taken.
+func (c *Client) CreateConfiguration(ctx context.Context, config types.Configuration) (*types.Configuration, error) {
+	var result struct {
+		Configuration types.Configuration `json:"configuration"`
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/cli/configurations", body: config, auth: true}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Configuration, nil
+}
+
+// UpdateConfiguration replaces the fields of a configuration. When
+// expectedUpdatedAt is set, the server rejects the update with 409
+// (ErrConflict) if the configuration changed since then. Changing the
+// project name renames the configuration; that is also rejected with 409
+// when the new name is taken or deployments are in progress.
+func (c *Client) UpdateConfiguration(ctx context.Context, projectName string, config types.Configuration, expectedUpdatedAt time.Time) (*types.Configuration, error) {
+	body := struct {
+		Configuration     types.Configuration `json:"configuration"`
+		ExpectedUpdatedAt *time.Time          `json:"expected_updated_at,omitempty"`
+	}{Configuration: config}
+	if !expectedUpdatedAt.IsZero() {
+		body.ExpectedUpdatedAt = &expectedUpdatedAt
+	}
+
+	var result struct {
+		Configuration types.Configuration `json:"configuration"`
+	}
+	path := "/api/cli/configurations/" + url.PathEscape(projectName)
+	if err := c.do(ctx, request{method: http.MethodPatch, path: path, body: body, auth: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result.Configuration, nil
+}
+
+// DeleteConfiguration removes a configuration. The server answers 409,
+// matched by ErrConflict, while deployments of it are in progress.
+func (c *Client) DeleteConfiguration(ctx context.Context, projectName string) error {
+	path := "/api/cli/configurations/" + url.PathEscape(projectName)
+	return c.do(ctx, request{method: http.MethodDelete, path: path, auth: true}, nil)
+}
+
+// WhoAmI asks the portal to check the current token and describe it.
+func (c *Client) WhoAmI(ctx context.Context) (*types.TokenInfo, error) {
+	var result types.TokenInfo
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/cli/whoami", auth: true}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTokens returns the user's API tokens that have not been revoked.
+func (c *Client) ListTokens(ctx context.Context) ([]types.APIToken, error) {
+	var result struct {
+		Tokens []types.APIToken `json:"tokens"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/cli/tokens", auth: true}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Tokens, nil
+}
+
+// CreateToken creates an API token and returns it along with the token
+// string, which cannot be retrieved again. The server answers 409, matched
+// by ErrConflict, when a token with the name exists.
+func (c *Client) CreateToken(ctx context.Context, name string, scopes []string, expiresAt time.Time) (*types.APIToken, string, error) {
+	body := map[string]any{"name": name, "scopes": scopes, "expires_at": expiresAt}
+	var result struct {
+		Token  types.APIToken `json:"token"`
+		Secret string         `json:"secret"`
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/cli/tokens", body: body, auth: true}, &result)
+	if err != nil {
+		return nil, "", err
+	}
+	if result.Secret == "" {
+		return nil, "", errors.New("server returned no token")
+	}
+	return &result.Token, result.Secret, nil
+}
+
+// RevokeToken revokes the API token with the given ID.
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	path := "/api/cli/tokens/" + url.PathEscape(id)
+	return c.do(ctx, request{method: http.MethodDelete, path: path, auth: true}, nil)
+}
+
+// ClaimDeployment moves the oldest queued deployment to PROCESSING and
+// returns it, or nil when the queue is empty. The claim is not retried, since
+// a claim that reached the server would leave the deployment stuck.
+func (c *Client) ClaimDeployment(ctx context.Context) (*types.Deployment, error) {
+	var result struct {
+		Deployment *types.Deployment `json:"deployment"`
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/cli/deployments/claim", auth: true}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Deployment, nil
+}
+
+// ReportDeployment sets the status of a claimed deployment to SUCCESS or
+// FAILED. message is stored as the error message of a failed deployment.
+func (c *Client) ReportDeployment(ctx context.Context, id, status, message string) error {
+	body := map[string]string{"status": status}
+	if message != "" {
+		body["error_message"] = message
+	}
+	path := "/api/cli/deployments/" + url.PathEscape(id)
+	return c.do(ctx, request{method: http.MethodPut, path: path, body: body, auth: true}, nil)
+}
+
+// DeploymentLogsURL returns the URL the logs of deployment id are shipped to
+// by an agent.
+func (c *Client) DeploymentLogsURL(id string) string {
+	return c.origin + "/api/cli/deployments/" + url.PathEscape(id) + "/logs"
+}
+
+// Authorize starts a device flow login. The user approves it by entering the
+// returned user code at the verification URI, possibly on another machine,
+// while the CLI polls Exchange with the device code.
+func (c *Client) Authorize(ctx context.Context) (*DeviceAuthorization, error) {
+	var result DeviceAuthorization
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/auth/cli/device", body: map[string]string{}}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.DeviceCode == "" || result.UserCode == "" || result.VerificationURI == "" {
+		return nil, errors.New("server returned an incomplete device authorization")
+	}
+	return &result, nil
+}
+
+// Exchange trades a device code for tokens once the user has approved the
+// login. Until then it returns ErrAuthorizationPending, or ErrSlowDown when
+// polled too often; ErrExpiredToken and ErrAccessDenied end the login.
+//
+// Once approved it returns ErrVerificationRequired while verificationCode is
+// empty, and ErrInvalidVerificationCode for a wrong one, until the server
+// gives up with ErrTooManyAttempts.
+func (c *Client) Exchange(ctx context.Context, deviceCode, verificationCode string) (*types.ExchangeResponse, error) {
+	var result types.ExchangeResponse
+	body := map[string]string{"grant_type": deviceCodeGrantType, "device_code": deviceCode}
+	if verificationCode != "" {
+		body["verification_code"] = verificationCode
+	}
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/auth/cli/exchange", body: body}, &result)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		switch apiErr.Message {
+		case "authorization_pending":
+			return nil, ErrAuthorizationPending
+		case "slow_down":
+			return nil, ErrSlowDown
+		case "expired_token":
+			return nil, ErrExpiredToken
+		case "access_denied":
+			return nil, ErrAccessDenied
+		case "verification_required":
+			return nil, ErrVerificationRequired
+		case "invalid_verification_code":
+			if apiErr.Description == "" {
+				return nil, ErrInvalidVerificationCode
+			}
+			return nil, &describedError{err: ErrInvalidVerificationCode, description: apiErr.Description}
+		case "too_many_attempts":
+			return nil, ErrTooManyAttempts
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeSession revokes the login session of a refresh token on the
+// server, so neither it nor its access tokens can be used again. Unknown
+// and expired tokens are not an error.
+func (c *Client) RevokeSession(ctx context.Context, refreshToken string) error {
+	body := map[string]string{"token": refreshToken, "token_type_hint": "refresh_token"}
+	return c.do(ctx, request{method: http.MethodPost, path: "/api/auth/cli/revoke", body: body, idempotent: true}, nil)
+}
+
+// RevokeAllSessions revokes every login session of the user, including the
+// current one, and returns how many were revoked. API tokens stay valid.
+func (c *Client) RevokeAllSessions(ctx context.Context) (int, error) {
+	var result struct {
+		Revoked int `json:"revoked"`
+	}
+	err := c.do(ctx, request{method: http.MethodDelete, path: "/api/cli/sessions", auth: true}, &result)
+	if err != nil {
+		return 0, err
+	}
+	return result.Revoked, nil
+}
+
+// KeySet returns the keys the portal signs login tokens with, for verifying
+// them locally.
+func (c *Client) KeySet(ctx context.Context) (*types.KeySet, error) {
+	var result types.KeySet
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/auth/cli/jwks"}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Issuer == "" {
+		return nil, errors.New("server returned a key set without an issuer")
+	}
+	return &result, nil
+}
+
+// Refresh returns a new access token for a refresh token. When the server
+// rotates refresh tokens it also returns the new refresh token, which
+// replaces the old one; otherwise newRefreshToken is empty.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (accessToken, newRefreshToken string, err error) {
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	body := map[string]string{"refresh_token": refreshToken}
+	err = c.do(ctx, request{method: http.MethodPost, path: "/api/auth/cli/refresh", body: body}, &result)
+	if err != nil {
+		return "", "", err
+	}
+	if result.AccessToken == "" {
+		return "", "", errors.New("server returned no access token")
+	}
+	return result.AccessToken, result.RefreshToken, nil
+}
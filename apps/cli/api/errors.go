@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotLoggedIn is returned for authenticated calls on a client without
+	// a token source.
+	ErrNotLoggedIn = errors.New("you are not logged in. Please run `grape login`")
+
+	// Matched by *Error through errors.Is.
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+// Error is a non-2xx response from the portal.
+type Error struct {
+	StatusCode int
+	// Message is the "error" field of the response body, if any.
+	Message string
+	// Description is the "error_description" field of OAuth style errors.
+	Description string
+}
+
+func newError(status int, body []byte) *Error {
+	e := &Error{StatusCode: status}
+
+	var payload struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		e.Message = payload.Error
+		e.Description = payload.Description
+	} else if text := strings.TrimSpace(string(body)); len(text) < 200 {
+		// Proxies answer with plain text; keep it when it is short.
+		e.Message = text
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Is lets callers test for ErrUnauthorized, ErrForbidden, ErrNotFound and
+// ErrConflict with errors.Is.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
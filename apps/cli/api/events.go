@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/imroc/req/v3"
+)
+
+// Event is a server-sent event.
+type Event struct {
+	Name string
+	Data []byte
+}
+
+// EventStream reads server-sent events from an open response.
+type EventStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// ConfigurationEvents subscribes to changes of the user's configurations.
+// The portal sends a "configuration" event for every insert, update or
+// delete. Servers without the stream answer 404, matched by ErrNotFound, and
+// callers should fall back to polling.
+func (c *Client) ConfigurationEvents(ctx context.Context) (*EventStream, error) {
+	if c.tokens == nil {
+		return nil, ErrNotLoggedIn
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.openStream(ctx, "/api/cli/events", token)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if token, err = c.tokens.Refresh(ctx, token); err != nil {
+			return nil, err
+		}
+		resp, err = c.openStream(ctx, "/api/cli/events", token)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsErrorState() {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, newError(resp.StatusCode, body)
+	}
+	if ct := resp.GetContentType(); !strings.HasPrefix(ct, "text/event-stream") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected content type %q for event stream", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &EventStream{body: resp.Body, scanner: scanner}, nil
+}
+
+func (c *Client) openStream(ctx context.Context, path, token string) (*req.Response, error) {
+	resp, err := c.stream.R().
+		SetContext(ctx).
+		SetBearerAuthToken(token).
+		SetHeader("Accept", "text/event-stream").
+		DisableAutoReadResponse().
+		Get(c.origin + path)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", c.origin, err)
+	}
+	return resp, nil
+}
+
+// Next blocks until the next event. It returns io.EOF when the server closes
+// the stream.
+func (s *EventStream) Next() (Event, error) {
+	var (
+		ev   Event
+		data bytes.Buffer
+	)
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if ev.Name == "" && data.Len() == 0 {
+				continue
+			}
+			if ev.Name == "" {
+				ev.Name = "message"
+			}
+			ev.Data = bytes.TrimSuffix(data.Bytes(), []byte("\n"))
+			return ev, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment, used by the server as a heartbeat.
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Name = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// Close ends the subscription.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/agent"
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run the Tendril agent that executes queued deployments",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Use `grape agent run` to start the agent")
+	},
+}
+
+var (
+	agentCfg       agent.Config
+	agentTokenFile string
+)
+
+var agentRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Poll for queued deployments and execute them one at a time",
+	Long: `Run the Tendril agent in the foreground.
+
+The agent authenticates with a machine token, an API token with the
+configs:write scope (see ` + "`grape token create`" + `), read from
+--agent-token-file or the TENDRIL_AGENT_TOKEN environment variable, never with
+the user credentials created by ` + "`grape login`" + `. It claims QUEUED deployments from the portal,
+runs Terraform, streams the output to the deployment logs and reports
+SUCCESS or FAILED. SIGINT/SIGTERM stop the current job and exit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := agentCfg
+
+		token, err := readAgentToken(agentTokenFile)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		cfg.Token = token
+
+		if cfg.Origin == "" {
+			cfg.Origin = os.Getenv("TENDRIL_ORIGIN")
+		}
+		if cfg.Origin == "" {
+			cfg.Origin = webOrigin()
+		}
+		cfg.Version = rootCmd.Version
+		if cfg.Deploy.GitToken == "" {
+			cfg.Deploy.GitToken = os.Getenv("GRAPE_GIT_TOKEN")
+		}
+		if cfg.Deploy.AWSProfile == "" && activeProfile != nil {
+			cfg.Deploy.AWSProfile = activeProfile.Defaults.AWSProfile
+		}
+		cfg.Reveal = revealSecrets
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := agent.New(cfg).Run(ctx); err != nil {
+			fmt.Printf("Agent error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// readAgentToken loads the machine token from a file, falling back to
+// TENDRIL_AGENT_TOKEN.
+func readAgentToken(path string) (string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading token file: %w", err)
+		}
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+
+	if token := os.Getenv("TENDRIL_AGENT_TOKEN"); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no machine token found. Set TENDRIL_AGENT_TOKEN or pass --agent-token-file")
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.AddCommand(agentRunCmd)
+	agentRunCmd.Flags().StringVar(&agentCfg.Origin, "origin", "", "URL of the portal to poll for deployments (default: $TENDRIL_ORIGIN or the profile origin)")
+	agentRunCmd.Flags().StringVar(&agentTokenFile, "agent-token-file", "", "File containing the machine token (default: $TENDRIL_AGENT_TOKEN)")
+	agentRunCmd.Flags().DurationVar(&agentCfg.PollInterval, "interval", 10*time.Second, "Wait between polls when no job is queued")
+	agentRunCmd.Flags().StringVar(&agentCfg.WorkDir, "work-dir", "", "Parent directory for job working directories (default: a grape-agent directory under the system temp dir)")
+	agentRunCmd.Flags().StringVar(&agentCfg.Deploy.AWSProfile, "aws-profile", "", "AWS profile to use for Terraform (default: the profile's aws_profile)")
+	agentRunCmd.Flags().StringVar(&agentCfg.Deploy.TerraformBin, "terraform", "", "Path to the terraform executable")
+}
@@ -0,0 +1,381 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/credentials"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/jwks"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/profiles"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of `grape auth status` and `grape whoami`.
+const (
+	exitNotAuthenticated = 1
+	// exitUnverified means the credentials look usable, but the portal
+	// could not be reached to confirm it.
+	exitUnverified = 2
+)
+
+// authStatus describes the credentials of the active profile.
+type authStatus struct {
+	Profile       string `json:"profile"`
+	Origin        string `json:"origin"`
+	Authenticated bool   `json:"authenticated"`
+	// Source is where the token was found: the credential store, GRAPE_TOKEN
+	// or the --token-file path.
+	Source string `json:"source,omitempty"`
+	Email  string `json:"email,omitempty"`
+	// TokenType is "login" for tokens from `grape login` and "api" for API
+	// tokens.
+	TokenType            string     `json:"token_type,omitempty"`
+	TokenName            string     `json:"token_name,omitempty"`
+	Scopes               []string   `json:"scopes,omitempty"`
+	AccessTokenExpiresAt *time.Time `json:"access_token_expires_at,omitempty"`
+	// AccessTokenError says why the access token failed the local check, or
+	// that it could not be checked.
+	AccessTokenError      string     `json:"access_token_error,omitempty"`
+	HasRefreshToken       bool       `json:"has_refresh_token"`
+	RefreshTokenExpiresAt *time.Time `json:"refresh_token_expires_at,omitempty"`
+	// Verified is set by the live check: whether the portal accepted the
+	// token. It is nil when the check was skipped or did not get an answer.
+	Verified *bool  `json:"verified,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// localAuthStatus inspects the token of the active profile without asking
+// the portal, checking login tokens against its signing keys. A stale copy
+// of the keys is used when the portal cannot be reached.
+func localAuthStatus(ctx context.Context) authStatus {
+	status := authStatus{Profile: activeProfileName, Origin: webOrigin()}
+	if status.Profile == "" {
+		status.Profile = profiles.DefaultName
+	}
+	verifier := tokenVerifier(anonymousClient())
+
+	token, source, err := providedToken()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if token != "" {
+		status.Source = source
+		status.describeAccessToken(ctx, verifier, token)
+		return status
+	}
+
+	store, err := credentialStore()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	creds, err := store.Load()
+	if errors.Is(err, credentials.ErrNotFound) {
+		status.Error = "not logged in"
+		return status
+	}
+	if err != nil {
+		status.Error = fmt.Sprintf("error reading credentials from the %s: %v", store.Name(), err)
+		return status
+	}
+
+	status.Source = store.Name()
+	status.Email = creds.UserEmail
+	accessValid := status.describeAccessToken(ctx, verifier, creds.AccessToken)
+	if status.TokenType != "login" {
+		return status
+	}
+
+	refreshValid := false
+	if creds.RefreshToken != "" {
+		status.HasRefreshToken = true
+		claims, err := verifier.Verify(ctx, creds.RefreshToken, "refresh")
+		if claims != nil && claims.ExpiresAt != nil {
+			status.RefreshTokenExpiresAt = &claims.ExpiresAt.Time
+		}
+		switch {
+		case err == nil:
+			refreshValid = true
+		case errors.Is(err, jwks.ErrWrongServer) && status.Authenticated:
+			status.Authenticated = false
+			status.Error = fmt.Sprintf("the refresh token was rejected: %v", err)
+		case !errors.Is(err, jwks.ErrExpired) && !errors.Is(err, jwks.ErrInvalid) && !errors.Is(err, jwks.ErrUnknownKey):
+			// The keys could not be fetched; leave it to the portal.
+			refreshValid = true
+		}
+	}
+	if !status.Authenticated {
+		return status
+	}
+
+	if !accessValid && !refreshValid {
+		status.Authenticated = false
+		status.Error = "the access token expired and cannot be refreshed"
+		if status.AccessTokenError != "" {
+			status.Error = "the access token is invalid and cannot be refreshed"
+		}
+	}
+	return status
+}
+
+// describeAccessToken fills in the type and expiry of an access token, and
+// reports whether it can be used without a refresh. A token that could not
+// be checked for lack of signing keys counts as usable, with a warning in
+// Error, and so does one whose signature only the portal can check.
+func (s *authStatus) describeAccessToken(ctx context.Context, verifier *jwks.Verifier, token string) bool {
+	if token == "" {
+		s.Error = "invalid credentials"
+		return false
+	}
+	s.Authenticated = true
+	if strings.HasPrefix(token, apiTokenPrefix) {
+		// Only the portal knows the expiry of API tokens.
+		s.TokenType = "api"
+		return true
+	}
+	s.TokenType = "login"
+
+	claims, err := verifier.Verify(ctx, token, "access")
+	if claims != nil && claims.ExpiresAt != nil {
+		s.AccessTokenExpiresAt = &claims.ExpiresAt.Time
+	}
+	switch {
+	case err == nil && claims.ClaimsOnly:
+		s.AccessTokenError = "signature not checked, the portal publishes no signing keys"
+		return true
+	case err == nil, errors.Is(err, jwks.ErrExpired):
+		return err == nil
+	case errors.Is(err, jwks.ErrUnknownKey):
+		s.AccessTokenError = err.Error()
+		return false
+	case errors.Is(err, jwks.ErrInvalid):
+		s.Authenticated = false
+		s.AccessTokenError = err.Error()
+		s.Error = "the access token is invalid. Run `grape login` again"
+		return false
+	case errors.Is(err, jwks.ErrWrongServer):
+		s.Authenticated = false
+		s.AccessTokenError = err.Error()
+		s.Error = fmt.Sprintf("the access token was rejected: %v", err)
+		return false
+	default:
+		s.AccessTokenError = "not checked, the signing keys are unavailable"
+		s.Error = err.Error()
+		return true
+	}
+}
+
+// verify asks the portal whether it accepts the token, which may refresh an
+// expired access token first. It returns the exit code for the result.
+func (s *authStatus) verify(ctx context.Context) int {
+	client, err := apiClient()
+	if err != nil {
+		s.Error = err.Error()
+		return exitNotAuthenticated
+	}
+
+	info, err := client.WhoAmI(ctx)
+	var apiErr *api.Error
+	switch {
+	case err == nil:
+	case errors.As(err, &apiErr), errors.Is(err, api.ErrUnauthorized):
+		// The portal answered, and did not accept the token.
+		verified := false
+		s.Authenticated = false
+		s.Verified = &verified
+		s.Error = err.Error()
+		return exitNotAuthenticated
+	default:
+		s.Error = fmt.Sprintf("could not reach %s: %v", s.Origin, err)
+		return exitUnverified
+	}
+
+	verified := true
+	s.Verified = &verified
+	s.Error = ""
+	if info.Email != "" {
+		s.Email = info.Email
+	}
+	s.TokenName = info.TokenName
+	s.Scopes = info.Scopes
+	if s.TokenType == "api" {
+		s.AccessTokenExpiresAt = info.ExpiresAt
+	}
+	return 0
+}
+
+// checkAuthStatus returns the status of the active profile, checked with the
+// portal unless offline, and the exit code for it.
+func checkAuthStatus(ctx context.Context, offline bool) (authStatus, int) {
+	status := localAuthStatus(ctx)
+	if !status.Authenticated {
+		return status, exitNotAuthenticated
+	}
+	if offline {
+		if status.Error != "" {
+			// The token could not be checked locally either.
+			return status, exitUnverified
+		}
+		return status, 0
+	}
+	code := status.verify(ctx)
+	return status, code
+}
+
+var authOffline bool
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect authentication",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Use `grape auth status`")
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show who you are logged in as and whether the token is valid",
+	Long: `Status shows the credentials of the active profile: the user, the portal,
+where the token is stored, when the access token expires and whether a
+refresh token is present. It then asks the portal whether it accepts the
+token, unless --offline is given.
+
+The exit code is 0 when authenticated, 1 when not, and 2 when the portal
+could not be reached to check.`,
+	Example: `  grape auth status
+  grape auth status -o json
+  grape auth status --offline && echo "logged in"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, code := checkAuthStatus(cmd.Context(), authOffline)
+		if structuredOutput() {
+			if err := printStructured(status); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			printAuthStatus(status)
+		}
+		if code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Print the email of the logged in user",
+	Long: `Whoami prints the email of the user the active profile is logged in as,
+checked with the portal unless --offline is given. Exit codes are those of
+` + "`grape auth status`" + `.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, code := checkAuthStatus(cmd.Context(), authOffline)
+		switch {
+		case structuredOutput():
+			if err := printStructured(status); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+		case code == exitUnverified:
+			fmt.Fprintf(os.Stderr, "Could not check authentication: %s\n", status.Error)
+		case code != 0:
+			fmt.Fprintf(os.Stderr, "Not authenticated: %s\n", status.Error)
+		case status.Email != "":
+			fmt.Println(status.Email)
+		case status.TokenName != "":
+			fmt.Printf("API token %s\n", status.TokenName)
+		default:
+			fmt.Printf("API token from %s\n", status.Source)
+		}
+		if code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+// printAuthStatus writes the status as aligned fields.
+func printAuthStatus(s authStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Profile:\t%s\n", s.Profile)
+	fmt.Fprintf(w, "Portal:\t%s\n", s.Origin)
+
+	if s.Source != "" {
+		if s.Email != "" {
+			fmt.Fprintf(w, "Logged in as:\t%s\n", s.Email)
+		}
+		fmt.Fprintf(w, "Credentials:\t%s\n", s.Source)
+		if s.TokenType == "api" {
+			name := s.TokenName
+			if name == "" {
+				name = "(unknown)"
+			}
+			fmt.Fprintf(w, "API token:\t%s\n", name)
+			if len(s.Scopes) > 0 {
+				fmt.Fprintf(w, "Scopes:\t%s\n", strings.Join(s.Scopes, ", "))
+			}
+		}
+		if s.AccessTokenError != "" {
+			fmt.Fprintf(w, "Access token:\t%s\n", s.AccessTokenError)
+		} else {
+			fmt.Fprintf(w, "Access token:\t%s\n", describeExpiry(s.AccessTokenExpiresAt, s.TokenType == "api"))
+		}
+		if s.TokenType == "login" {
+			refresh := "none"
+			switch {
+			case s.HasRefreshToken && s.RefreshTokenExpiresAt == nil:
+				refresh = "present, not checked"
+			case s.HasRefreshToken:
+				refresh = describeExpiry(s.RefreshTokenExpiresAt, false)
+			}
+			fmt.Fprintf(w, "Refresh token:\t%s\n", refresh)
+		}
+	}
+
+	switch {
+	case s.Verified != nil && *s.Verified:
+		fmt.Fprintf(w, "Server check:\t✓ token accepted\n")
+	case s.Verified != nil:
+		fmt.Fprintf(w, "Server check:\t✗ %s\n", s.Error)
+	case s.Error != "" && s.Authenticated:
+		fmt.Fprintf(w, "Server check:\t? %s\n", s.Error)
+	}
+	w.Flush()
+
+	if !s.Authenticated {
+		fmt.Println()
+		if s.Error != "" && s.Verified == nil {
+			fmt.Printf("✗ Not authenticated: %s.\n", s.Error)
+		}
+		fmt.Println("Run `grape login` to log in.")
+	}
+}
+
+// describeExpiry says how long a token is valid. API tokens have no local
+// expiry until the portal reports it.
+func describeExpiry(exp *time.Time, apiToken bool) string {
+	switch {
+	case exp == nil && apiToken:
+		return "expiry known to the portal only"
+	case exp == nil:
+		return "no expiry"
+	case exp.Before(time.Now()):
+		return fmt.Sprintf("expired %s (%s)", humanize.Time(*exp), exp.Local().Format("2006-01-02 15:04"))
+	default:
+		return fmt.Sprintf("valid, expires %s (%s)", humanize.Time(*exp), exp.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd, whoamiCmd)
+	authCmd.AddCommand(authStatusCmd)
+	for _, c := range []*cobra.Command{authStatusCmd, whoamiCmd} {
+		c.Flags().BoolVar(&authOffline, "offline", false, "only inspect the stored token, without asking the portal")
+	}
+}
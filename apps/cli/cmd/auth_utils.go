@@ -1,127 +1,266 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/credentials"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/jwks"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/profiles"
 	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/imroc/req/v3"
 )
 
-func getCredentialsPath() (string, error) {
-	configDir, err := os.UserConfigDir()
+// credentialStore opens the credential store of the active profile, moving a
+// plaintext credentials.json left by older versions into the default
+// profile on first use.
+func credentialStore() (credentials.Store, error) {
+	name := activeProfileName
+	if name == "" {
+		name = profiles.DefaultName
+	}
+
+	store, err := openProfileStore(name)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if name != profiles.DefaultName {
+		return store, nil
 	}
-	return filepath.Join(configDir, "grape", "credentials.json"), nil
-}
 
-func getAuthToken() (string, error) {
-	credsPath, err := getCredentialsPath()
+	dir, err := credentials.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("error getting config directory: %w", err)
+	}
+	migrated, err := credentials.MigrateLegacy(dir, store)
 	if err != nil {
-		return "", fmt.Errorf("error getting credentials path: %w", err)
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if migrated {
+		fmt.Fprintf(os.Stderr, "Moved credentials.json to the %s.\n", store.Name())
 	}
+	return store, nil
+}
 
-	if _, err := os.Stat(credsPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("you are not logged in. Please run `grape login`")
+// openProfileStore opens the credential store of the named profile.
+func openProfileStore(name string) (credentials.Store, error) {
+	dir, err := credentials.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("error getting config directory: %w", err)
 	}
+	return credentials.Open(dir, name)
+}
 
-	file, err := os.ReadFile(credsPath)
+// loadCredentials returns the stored credentials, or an error telling the
+// user to log in.
+func loadCredentials(store credentials.Store) (*types.ExchangeResponse, error) {
+	creds, err := store.Load()
+	if errors.Is(err, credentials.ErrNotFound) {
+		return nil, fmt.Errorf("you are not logged in. Please run `grape login`")
+	}
 	if err != nil {
-		return "", fmt.Errorf("error reading credentials file: %w", err)
+		return nil, fmt.Errorf("error reading credentials from the %s: %w", store.Name(), err)
 	}
+	return creds, nil
+}
 
-	var creds types.ExchangeResponse
-	if err := json.Unmarshal(file, &creds); err != nil {
-		return "", fmt.Errorf("error parsing credentials file: %w", err)
+// tokenEnv holds an API token, for CI, which is used instead of the stored
+// credentials.
+const tokenEnv = "GRAPE_TOKEN"
+
+// tokenFile is the global --token-file flag.
+var tokenFile string
+
+// apiTokenPrefix starts the API tokens created by `grape token create`.
+// Unlike login tokens they are not JWTs and cannot be refreshed.
+const apiTokenPrefix = "grape_"
+
+// providedToken returns the token from --token-file or GRAPE_TOKEN, in that
+// order, and where it came from. It returns "" when neither is set.
+func providedToken() (token, source string, err error) {
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", "", fmt.Errorf("error reading --token-file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return "", "", fmt.Errorf("--token-file %s is empty", tokenFile)
+		}
+		return token, tokenFile, nil
+	}
+	if token := strings.TrimSpace(os.Getenv(tokenEnv)); token != "" {
+		return token, tokenEnv, nil
 	}
+	return "", "", nil
+}
 
-	if creds.AccessToken == "" {
-		return "", fmt.Errorf("invalid credentials file. Please run `grape login` again")
+// tokenSource returns the token given by --token-file or GRAPE_TOKEN, or
+// else the stored credentials of the active profile.
+func tokenSource() (api.TokenSource, error) {
+	token, source, err := providedToken()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		return &fixedToken{token: token, source: source}, nil
 	}
 
-	// Check expiration
-	claims := jwt.MapClaims{}
-	_, _, err = jwt.NewParser().ParseUnverified(creds.AccessToken, claims)
+	store, err := credentialStore()
 	if err != nil {
-		return "", fmt.Errorf("error parsing token: %w", err)
+		return nil, err
 	}
+	return &storeTokens{store: store, client: anonymousClient()}, nil
+}
 
-	var exp int64
-	switch v := claims["exp"].(type) {
-	case float64:
-		exp = int64(v)
-	case json.Number:
-		exp, _ = v.Int64()
+// apiClient returns a client for the active profile's portal, authenticated
+// with the token from tokenSource.
+func apiClient() (*api.Client, error) {
+	tokens, err := tokenSource()
+	if err != nil {
+		return nil, err
 	}
+	return api.New(api.Config{
+		Origin:  webOrigin(),
+		Version: rootCmd.Version,
+		Tokens:  tokens,
+	}), nil
+}
 
-	// If expired (or expiring in < 1 minute), try to refresh
-	if time.Unix(exp, 0).Before(time.Now().Add(1 * time.Minute)) {
-		if creds.RefreshToken == "" {
-			return "", fmt.Errorf("token expired and no refresh token found. Please run `grape login` again")
-		}
-		
-		fmt.Println("Access token expired, refreshing...")
-		newAccessToken, err := refreshAccessToken(creds.RefreshToken)
-		if err != nil {
-			return "", fmt.Errorf("failed to refresh token: %w. Please run `grape login` again", err)
-		}
+// anonymousClient returns a client for endpoints that need no token.
+func anonymousClient() *api.Client {
+	return api.New(api.Config{Origin: webOrigin(), Version: rootCmd.Version})
+}
 
-		creds.AccessToken = newAccessToken
-		if err := saveCredentials(credsPath, creds); err != nil {
-			return "", fmt.Errorf("failed to save new credentials: %w", err)
-		}
-		
-		return newAccessToken, nil
+func getAuthToken() (string, error) {
+	tokens, err := tokenSource()
+	if err != nil {
+		return "", err
 	}
+	return tokens.Token(context.Background())
+}
 
-	return creds.AccessToken, nil
+// fixedToken is an api.TokenSource for a token given by --token-file or
+// GRAPE_TOKEN. It is never refreshed.
+type fixedToken struct {
+	token  string
+	source string
+}
+
+func (f *fixedToken) Token(ctx context.Context) (string, error) {
+	return f.token, nil
+}
+
+func (f *fixedToken) Refresh(ctx context.Context, rejected string) (string, error) {
+	return "", fmt.Errorf("the token from %s was rejected; it may be expired or revoked", f.source)
+}
+
+// storeTokens is an api.TokenSource backed by a credential store. Refreshed
+// access tokens are written back to the store. Refreshes hold the store's
+// lock, so when several grape processes find the token expired at once only
+// the first refreshes it and the others pick up its result.
+type storeTokens struct {
+	store  credentials.Store
+	client *api.Client
 }
 
-func refreshAccessToken(refreshToken string) (string, error) {
-	webOrigin := os.Getenv("GRAPE_WEB_ORIGIN")
-	if webOrigin == "" {
-		webOrigin = "https://localhost:3000"
+func (s *storeTokens) Token(ctx context.Context) (string, error) {
+	creds, err := loadCredentials(s.store)
+	if err != nil {
+		return "", err
 	}
-	refreshURL := fmt.Sprintf("%s/api/auth/cli/refresh", webOrigin)
 
-	client := req.C()
-	var result struct {
-		AccessToken string `json:"access_token"`
+	if creds.AccessToken == "" {
+		return "", fmt.Errorf("invalid credentials. Please run `grape login` again")
 	}
-	var errMsg struct {
-		Error string `json:"error"`
+	if strings.HasPrefix(creds.AccessToken, apiTokenPrefix) {
+		// Stored by `grape login --token`; the server checks its expiry.
+		return creds.AccessToken, nil
 	}
 
-	resp, err := client.R().
-		SetBody(map[string]string{"refresh_token": refreshToken}).
-		SetSuccessResult(&result).
-		SetErrorResult(&errMsg).
-		Post(refreshURL)
+	claims, err := tokenVerifier(s.client).Verify(ctx, creds.AccessToken, "access")
+	switch {
+	case errors.Is(err, jwks.ErrExpired):
+		fmt.Fprintln(os.Stderr, "Access token expired, refreshing...")
+		return s.refresh(ctx, creds)
+	case errors.Is(err, jwks.ErrUnknownKey):
+		// The portal retired the key; it decides whether the refresh token
+		// is still good.
+		fmt.Fprintln(os.Stderr, "Access token signed with a retired key, refreshing...")
+		return s.refresh(ctx, creds)
+	case errors.Is(err, jwks.ErrInvalid):
+		return "", fmt.Errorf("the stored access token is invalid: %w. Run `grape login` again", err)
+	case errors.Is(err, jwks.ErrWrongServer):
+		return "", fmt.Errorf("the stored access token was rejected: %w. Run `grape login` for %s", err, s.client.Origin())
+	case err != nil:
+		return "", fmt.Errorf("error verifying the access token: %w", err)
+	}
 
-	if err != nil {
-		return "", err
+	// Refresh a minute early, so the token does not expire in flight
+	if claims.ExpiresAt.Before(time.Now().Add(1 * time.Minute)) {
+		fmt.Fprintln(os.Stderr, "Access token expired, refreshing...")
+		return s.refresh(ctx, creds)
 	}
 
-	if resp.IsErrorState() {
-		return "", fmt.Errorf("server returned %d: %s", resp.StatusCode, errMsg.Error)
+	return creds.AccessToken, nil
+}
+
+// tokenVerifier checks login tokens against the signing keys of the
+// client's portal, cached in the config directory. The check only decides
+// when to refresh, since the portal verifies every request, so the tokens of
+// a portal that publishes no keys are accepted on their claims alone.
+func tokenVerifier(client *api.Client) *jwks.Verifier {
+	v := &jwks.Verifier{Origin: client.Origin(), Fetch: client.KeySet, AllowClaimsOnly: true}
+	if dir, err := credentials.Dir(); err == nil {
+		v.CacheDir = filepath.Join(dir, "jwks")
 	}
+	return v
+}
 
-	return result.AccessToken, nil
+func (s *storeTokens) Refresh(ctx context.Context, rejected string) (string, error) {
+	return s.refresh(ctx, &types.ExchangeResponse{AccessToken: rejected})
 }
 
-func saveCredentials(path string, creds types.ExchangeResponse) error {
-	file, err := os.Create(path)
+// refresh replaces the access token of stale, unless another process did so
+// while this one waited for the lock.
+func (s *storeTokens) refresh(ctx context.Context, stale *types.ExchangeResponse) (string, error) {
+	if strings.HasPrefix(stale.AccessToken, apiTokenPrefix) {
+		return "", fmt.Errorf("the API token was rejected; it may be expired or revoked. Run `grape login` again")
+	}
+
+	unlock, err := s.store.Lock(ctx)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("error locking the %s: %w", s.store.Name(), err)
 	}
-	defer file.Close()
+	defer unlock()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(creds)
-}
\ No newline at end of file
+	creds, err := loadCredentials(s.store)
+	if err != nil {
+		return "", err
+	}
+	if creds.AccessToken != stale.AccessToken {
+		return creds.AccessToken, nil
+	}
+	if creds.RefreshToken == "" {
+		return "", fmt.Errorf("token expired and no refresh token found. Please run `grape login` again")
+	}
+
+	newAccessToken, newRefreshToken, err := s.client.Refresh(ctx, creds.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh token: %w. Please run `grape login` again", err)
+	}
+
+	creds.AccessToken = newAccessToken
+	if newRefreshToken != "" {
+		creds.RefreshToken = newRefreshToken
+	}
+	if err := s.store.Save(*creds); err != nil {
+		return "", fmt.Errorf("failed to save new credentials: %w", err)
+	}
+
+	return newAccessToken, nil
+}
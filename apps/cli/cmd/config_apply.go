@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/configdiff"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfschema"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/spf13/cobra"
+)
+
+// Actions in an apply plan.
+const (
+	applyCreate    = "create"
+	applyUpdate    = "update"
+	applyUnchanged = "unchanged"
+)
+
+var (
+	applyFiles  []string
+	applyDryRun bool
+)
+
+// applyStep is the planned change for one configuration file.
+type applyStep struct {
+	File        string              `json:"file"`
+	ProjectName string              `json:"project_name"`
+	Environment string              `json:"environment_stage"`
+	Action      string              `json:"action"`
+	Changes     []configdiff.Change `json:"changes,omitempty"`
+
+	desired types.Configuration
+	current *types.Configuration
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <file|dir>...",
+	Short: "Create or update configurations from files",
+	Long: `Apply reconciles configuration files with the portal. Each file holds one
+configuration in YAML or JSON with the API field names, as written by
+` + "`grape config get -o yaml`" + `. A directory applies every .yaml, .yml and
+.json file in it.
+
+A file is matched to an existing configuration by project_name and
+environment_stage. Matching configurations are updated to the file's
+contents and the others are created. Fields maintained by the portal, like
+id and updated_at, are ignored.
+
+The plan is printed before anything is changed. All files are validated
+first and nothing is applied if any of them has errors.`,
+	Example: `  grape config apply -f my-project.yaml
+  grape config apply -f configs/ --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(applyFiles) == 0 {
+			fmt.Println("No files given. Use -f <file|dir>.")
+			os.Exit(1)
+		}
+
+		paths, err := expandApplyFiles(applyFiles)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		plan, err := planApply(cmd.Context(), client, paths)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if structuredOutput() {
+			if err := printStructured(plan); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			printPlan(plan)
+		}
+		if applyDryRun {
+			return
+		}
+
+		for _, step := range plan {
+			if err := step.apply(cmd.Context(), client); err != nil {
+				fmt.Printf("Error applying %s: %v\n", step.File, err)
+				os.Exit(1)
+			}
+			if !structuredOutput() && step.Action != applyUnchanged {
+				fmt.Printf("✓ %s %s\n", pastTense(step.Action), step.ProjectName)
+			}
+		}
+	},
+}
+
+// expandApplyFiles replaces directories with the configuration files in them.
+func expandApplyFiles(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !e.IsDir() {
+					paths = append(paths, filepath.Join(arg, e.Name()))
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no .yaml, .yml or .json files in %s", arg)
+		}
+	}
+	return paths, nil
+}
+
+// planApply reads and validates every file and works out what applying it
+// would change.
+func planApply(ctx context.Context, client *api.Client, paths []string) ([]applyStep, error) {
+	list, err := client.ListConfigurations(ctx, api.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching configurations: %w", err)
+	}
+	summaries := list.Configurations
+
+	var (
+		plan   []applyStep
+		seen   = map[string]string{}
+		failed bool
+	)
+	for _, path := range paths {
+		desired, err := readConfigurationFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if desired.ProjectName == "" || desired.EnvironmentStage == "" {
+			return nil, fmt.Errorf("%s: project_name and environment_stage are required", path)
+		}
+		if other, ok := seen[desired.ProjectName]; ok {
+			return nil, fmt.Errorf("%s: project %s is also defined in %s", path, desired.ProjectName, other)
+		}
+		seen[desired.ProjectName] = path
+
+		problems, err := validateConfiguration(*desired)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(problems) > 0 && !structuredOutput() {
+			fmt.Printf("%s:\n", path)
+			printProblems(problems, false)
+		}
+		failed = failed || tfschema.HasErrors(problems)
+
+		step := applyStep{
+			File:        path,
+			ProjectName: desired.ProjectName,
+			Environment: desired.EnvironmentStage,
+			Action:      applyCreate,
+			desired:     *desired,
+		}
+
+		i := slices.IndexFunc(summaries, func(s types.ConfigurationSummary) bool {
+			return s.ProjectName == desired.ProjectName
+		})
+		if i >= 0 {
+			if stage := summaries[i].EnvironmentStage; stage != desired.EnvironmentStage {
+				// Project names are unique on the portal, so this can be
+				// neither created nor updated.
+				return nil, fmt.Errorf("%s: project %s already exists with environment_stage %s", path, desired.ProjectName, stage)
+			}
+			current, err := client.GetConfigurationByProjectName(ctx, desired.ProjectName)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching %s: %w", desired.ProjectName, err)
+			}
+			if err := restoreMasked(desired, current); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			step.desired = *desired
+			changes, err := configdiff.Compare(*current, *desired, configdiff.Options{Ignore: configdiff.MetadataFields})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			step.current = current
+			step.Changes = redactChanges(changes)
+			step.Action = applyUpdate
+			if len(changes) == 0 {
+				step.Action = applyUnchanged
+			}
+		} else {
+			if err := restoreMasked(desired, nil); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			step.desired = *desired
+		}
+		plan = append(plan, step)
+	}
+
+	if failed {
+		return nil, errors.New("validation failed, nothing was applied")
+	}
+	return plan, nil
+}
+
+func (s applyStep) apply(ctx context.Context, client *api.Client) error {
+	switch s.Action {
+	case applyCreate:
+		_, err := client.CreateConfiguration(ctx, s.desired)
+		return err
+	case applyUpdate:
+		_, err := client.UpdateConfiguration(ctx, s.ProjectName, s.desired, s.current.UpdatedAt)
+		if errors.Is(err, api.ErrConflict) {
+			return fmt.Errorf("%s was changed on the portal since the plan was made, run apply again", s.ProjectName)
+		}
+		return err
+	}
+	return nil
+}
+
+func printPlan(plan []applyStep) {
+	counts := map[string]int{}
+	for _, step := range plan {
+		counts[step.Action]++
+		title := fmt.Sprintf("%s (%s) from %s", step.ProjectName, step.Environment, step.File)
+		switch step.Action {
+		case applyCreate:
+			fmt.Println(diffAddedStyle.Render("+ create " + title))
+		case applyUpdate:
+			fmt.Println(diffPathStyle.Render("~ update " + title))
+			for _, c := range step.Changes {
+				switch c.Kind {
+				case configdiff.Removed:
+					fmt.Println(diffRemovedStyle.Render(fmt.Sprintf("    - %s: %s", c.Path, c.Old)))
+				case configdiff.Added:
+					fmt.Println(diffAddedStyle.Render(fmt.Sprintf("    + %s: %s", c.Path, c.New)))
+				default:
+					fmt.Printf("    ~ %s: %s → %s\n", c.Path, diffRemovedStyle.Render(c.Old), diffAddedStyle.Render(c.New))
+				}
+			}
+		default:
+			fmt.Println(diffMutedStyle.Render("  unchanged " + title))
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Plan: %d to create, %d to update, %d unchanged.\n",
+		counts[applyCreate], counts[applyUpdate], counts[applyUnchanged])
+	if applyDryRun {
+		fmt.Println(diffMutedStyle.Render("Dry run, nothing was applied."))
+	}
+}
+
+// restoreMasked puts back the credentials masked in a file written without
+// --reveal, taking them from current, the configuration on the portal. It is
+// an error if current is nil or lacks one of them.
+func restoreMasked(desired, current *types.Configuration) error {
+	data, err := json.Marshal(desired)
+	if err != nil || !bytes.Contains(data, []byte(redact.Mask)) {
+		return err
+	}
+
+	var d, c any
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	if current != nil {
+		cur, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(cur, &c); err != nil {
+			return err
+		}
+	}
+	if missing := redact.Restore(d, c); len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("%s %s masked and not set on the portal; write the file with --reveal or fill in the value",
+			strings.Join(missing, ", "), pluralVerb(len(missing)))
+	}
+
+	if data, err = json.Marshal(d); err != nil {
+		return err
+	}
+	var restored types.Configuration
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return err
+	}
+	*desired = restored
+	return nil
+}
+
+func pluralVerb(n int) string {
+	if n == 1 {
+		return "is"
+	}
+	return "are"
+}
+
+func pastTense(action string) string {
+	if action == applyCreate {
+		return "Created"
+	}
+	return "Updated"
+}
+
+func init() {
+	configCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringArrayVarP(&applyFiles, "filename", "f", nil, "configuration file or directory to apply (repeatable)")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "print the plan without applying it")
+}
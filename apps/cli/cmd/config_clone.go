@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneTo    string
+	cloneStage string
+)
+
+// stageAliases maps the short environment names accepted by --stage.
+var stageAliases = map[string]string{
+	"dev":   "development",
+	"stage": "staging",
+	"stg":   "staging",
+	"prod":  "production",
+}
+
+var configCloneCmd = &cobra.Command{
+	Use:     "clone [project_name] --to [new_name]",
+	Short:   "Copy a configuration as a new project or environment",
+	Example: `  grape config clone my-project --to my-project-prod --stage prod`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := args[0]
+
+		if err := validateProjectName(cloneTo); err != nil {
+			fmt.Printf("Invalid project name %q: %v\n", cloneTo, err)
+			os.Exit(1)
+		}
+		stage, err := normalizeStage(cloneStage)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		source, err := client.GetConfigurationByProjectName(cmd.Context(), projectName)
+		if errors.Is(err, api.ErrNotFound) || (err == nil && source.ID == "") {
+			fmt.Printf("No configuration found for project: %s\n", projectName)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error fetching configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ensureProjectNameFree(cmd, client, cloneTo); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		clone := *source
+		clone.ProjectName = cloneTo
+		if stage != "" {
+			clone.EnvironmentStage = stage
+		}
+
+		created, err := client.CreateConfiguration(cmd.Context(), clone)
+		if errors.Is(err, api.ErrConflict) {
+			fmt.Printf("Cannot clone to %s: %s\n", cloneTo, serverMessage(err))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error creating configuration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Cloned %s to %s (%s).\n", projectName, created.ProjectName, created.EnvironmentStage)
+	},
+}
+
+// normalizeStage expands short names like "prod" and rejects unknown stages.
+// An empty stage is returned as is.
+func normalizeStage(stage string) (string, error) {
+	stage = strings.ToLower(strings.TrimSpace(stage))
+	if full, ok := stageAliases[stage]; ok {
+		return full, nil
+	}
+	if stage == "" || slices.Contains(environmentStages, stage) {
+		return stage, nil
+	}
+	return "", fmt.Errorf("unknown environment stage %q (expected %s)", stage, strings.Join(environmentStages, ", "))
+}
+
+func init() {
+	configCmd.AddCommand(configCloneCmd)
+	configCloneCmd.Flags().StringVar(&cloneTo, "to", "", "project name of the copy")
+	configCloneCmd.Flags().StringVar(&cloneStage, "stage", "", "environment stage of the copy: development, staging or production (default: the source's)")
+	configCloneCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfschema"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+// Choices offered by the portal's configuration form.
+var (
+	environmentStages  = []string{"development", "staging", "production"}
+	containerPlatforms = []string{"standard", "ai-workloads", "custom"}
+	terraformVersions  = []string{"1.5.0", "1.4.6", "1.3.9"}
+	awsRegions         = []string{"us-east-1", "us-east-2", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-1"}
+)
+
+var (
+	projectNamePattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	awsAccountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+// createForm holds the answers of the create wizard.
+type createForm struct {
+	projectName       string
+	environmentStage  string
+	containerPlatform string
+	description       string
+
+	awsAccountID string
+	awsRegion    string
+
+	createVpc     bool
+	vpcCidr       string
+	enableDns     bool
+	dnsHostedZone string
+	dnsDomainName string
+
+	dbMinCapacity string
+	dbMaxCapacity string
+
+	enableCloudfrontWaf    bool
+	enableRedis            bool
+	redisAllowedCidrBlocks string
+
+	enableKarpenter  bool
+	terraformVersion string
+	eksClusterAdmins string
+}
+
+var configCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a configuration with an interactive wizard",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !interactive() {
+			fmt.Println("`grape config create` needs an interactive terminal.")
+			os.Exit(1)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		f := &createForm{
+			environmentStage:  environmentStages[0],
+			containerPlatform: containerPlatforms[0],
+			awsRegion:         "us-east-1",
+			createVpc:         true,
+			vpcCidr:           "10.0.0.0/16",
+			dbMinCapacity:     "2",
+			dbMaxCapacity:     "16",
+			enableKarpenter:   true,
+			terraformVersion:  terraformVersions[0],
+		}
+		if err := f.form().Run(); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				fmt.Println("Aborted.")
+				return
+			}
+			fmt.Printf("Error running wizard: %v\n", err)
+			os.Exit(1)
+		}
+
+		config := f.configuration()
+		printConfiguration(config)
+
+		problems, err := validateConfiguration(config)
+		if err != nil {
+			fmt.Printf("Error validating configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if len(problems) > 0 {
+			printProblems(problems, false)
+			fmt.Println()
+		}
+
+		confirmed := false
+		message := "Create this configuration?"
+		if tfschema.HasErrors(problems) {
+			message = "The configuration has errors. Create it anyway?"
+		}
+		survey.AskOne(&survey.Confirm{Message: message, Default: !tfschema.HasErrors(problems)}, &confirmed)
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return
+		}
+
+		created, err := client.CreateConfiguration(cmd.Context(), config)
+		if errors.Is(err, api.ErrConflict) {
+			fmt.Printf("A configuration named %s already exists. Use `grape config edit %s` to change it.\n", config.ProjectName, config.ProjectName)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error creating configuration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Created configuration %s (%s).\n", created.ProjectName, created.ID)
+	},
+}
+
+// form builds the wizard, one group per section of `grape config get`.
+func (f *createForm) form() *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().Title("Project name").
+				Description("Lowercase letters, digits and dashes.").
+				Value(&f.projectName).
+				Validate(validateProjectName),
+			huh.NewSelect[string]().Title("Environment").
+				Options(huh.NewOptions(environmentStages...)...).
+				Value(&f.environmentStage),
+			huh.NewSelect[string]().Title("Container platform").
+				Options(huh.NewOptions(containerPlatforms...)...).
+				Value(&f.containerPlatform),
+			huh.NewText().Title("Description").
+				Description("Optional.").
+				Value(&f.description),
+		).Title("General"),
+
+		huh.NewGroup(
+			huh.NewInput().Title("AWS account ID").
+				Value(&f.awsAccountID).
+				Validate(validateAWSAccountID),
+			huh.NewSelect[string]().Title("Region").
+				Options(huh.NewOptions(awsRegions...)...).
+				Value(&f.awsRegion),
+		).Title("AWS Configuration"),
+
+		huh.NewGroup(
+			huh.NewConfirm().Title("Create a new VPC?").
+				Value(&f.createVpc),
+			huh.NewConfirm().Title("Enable DNS?").
+				Description("Creates an ACM certificate for the hosted zone.").
+				Value(&f.enableDns),
+		).Title("Network Configuration"),
+
+		huh.NewGroup(
+			huh.NewInput().Title("VPC CIDR").
+				Value(&f.vpcCidr).
+				Validate(validateCIDR),
+		).Title("Network Configuration").
+			WithHideFunc(func() bool { return !f.createVpc }),
+
+		huh.NewGroup(
+			huh.NewInput().Title("Hosted zone").
+				Value(&f.dnsHostedZone).
+				Validate(required("hosted zone")),
+			huh.NewInput().Title("Domain name").
+				Value(&f.dnsDomainName).
+				Validate(required("domain name")),
+		).Title("Network Configuration").
+			WithHideFunc(func() bool { return !f.enableDns }),
+
+		huh.NewGroup(
+			huh.NewInput().Title("Minimum capacity (ACUs)").
+				Value(&f.dbMinCapacity).
+				Validate(validateCapacity),
+			huh.NewInput().Title("Maximum capacity (ACUs)").
+				Value(&f.dbMaxCapacity).
+				Validate(func(s string) error {
+					if err := validateCapacity(s); err != nil {
+						return err
+					}
+					lo, _ := strconv.Atoi(f.dbMinCapacity)
+					if hi, _ := strconv.Atoi(s); hi < lo {
+						return fmt.Errorf("must be at least the minimum capacity (%d)", lo)
+					}
+					return nil
+				}),
+		).Title("Database Configuration"),
+
+		huh.NewGroup(
+			huh.NewConfirm().Title("Enable CloudFront WAF?").
+				Value(&f.enableCloudfrontWaf),
+			huh.NewConfirm().Title("Enable Redis?").
+				Value(&f.enableRedis),
+		).Title("Security"),
+
+		huh.NewGroup(
+			huh.NewInput().Title("Redis allowed CIDR blocks").
+				Description("Comma separated.").
+				Value(&f.redisAllowedCidrBlocks).
+				Validate(validateCIDRList),
+		).Title("Security").
+			WithHideFunc(func() bool { return !f.enableRedis }),
+
+		huh.NewGroup(
+			huh.NewConfirm().Title("Enable Karpenter auto-scaling?").
+				Value(&f.enableKarpenter),
+			huh.NewSelect[string]().Title("Terraform version").
+				Options(huh.NewOptions(terraformVersions...)...).
+				Value(&f.terraformVersion),
+			huh.NewText().Title("EKS cluster admins").
+				Description("Optional. A YAML list of IAM usernames.").
+				Value(&f.eksClusterAdmins),
+		).Title("Advanced"),
+	)
+}
+
+// configuration converts the answers into a configuration.
+func (f *createForm) configuration() types.Configuration {
+	config := types.Configuration{
+		ProjectName:         strings.TrimSpace(f.projectName),
+		EnvironmentStage:    f.environmentStage,
+		ContainerPlatform:   f.containerPlatform,
+		Description:         optionalString(f.description),
+		AwsAccountID:        strings.TrimSpace(f.awsAccountID),
+		AwsRegion:           f.awsRegion,
+		CreateVpc:           &f.createVpc,
+		EnableDns:           &f.enableDns,
+		EnableCloudfrontWaf: &f.enableCloudfrontWaf,
+		EnableRedis:         &f.enableRedis,
+		EnableKarpenter:     &f.enableKarpenter,
+		TerraformVersion:    f.terraformVersion,
+		EksClusterAdmins:    optionalString(f.eksClusterAdmins),
+	}
+	if f.createVpc {
+		config.VpcCidr = optionalString(f.vpcCidr)
+	}
+	if f.enableDns {
+		config.DnsHostedZone = optionalString(f.dnsHostedZone)
+		config.DnsDomainName = optionalString(f.dnsDomainName)
+	}
+	if f.enableRedis {
+		config.RedisAllowedCidrBlocks = optionalString(f.redisAllowedCidrBlocks)
+	}
+	if n, err := strconv.Atoi(f.dbMinCapacity); err == nil {
+		config.DbMinCapacity = &n
+	}
+	if n, err := strconv.Atoi(f.dbMaxCapacity); err == nil {
+		config.DbMaxCapacity = &n
+	}
+	return config
+}
+
+func optionalString(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func required(name string) func(string) error {
+	return func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+}
+
+func validateProjectName(s string) error {
+	if !projectNamePattern.MatchString(strings.TrimSpace(s)) {
+		return errors.New("use lowercase letters, digits and dashes")
+	}
+	return nil
+}
+
+func validateAWSAccountID(s string) error {
+	if !awsAccountIDPattern.MatchString(strings.TrimSpace(s)) {
+		return errors.New("must be 12 digits")
+	}
+	return nil
+}
+
+func validateCIDR(s string) error {
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return errors.New("not a valid CIDR block, e.g. 10.0.0.0/16")
+	}
+	if prefix.Masked() != prefix {
+		return fmt.Errorf("host bits are set, did you mean %s?", prefix.Masked())
+	}
+	return nil
+}
+
+func validateCIDRList(s string) error {
+	for _, block := range strings.Split(s, ",") {
+		if err := validateCIDR(block); err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimSpace(block), err)
+		}
+	}
+	return nil
+}
+
+func validateCapacity(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return errors.New("must be a whole number")
+	}
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(configCreateCmd)
+}
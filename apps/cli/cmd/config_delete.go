@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/spf13/cobra"
+)
+
+var deleteYes bool
+
+var configDeleteCmd = &cobra.Command{
+	Use:   "delete [project_name]",
+	Short: "Delete a configuration",
+	Long: `Delete removes a configuration from the portal. It does not destroy
+infrastructure that was deployed from it.
+
+A configuration with deployments in progress cannot be deleted.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := args[0]
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		config, err := client.GetConfigurationByProjectName(cmd.Context(), projectName)
+		if errors.Is(err, api.ErrNotFound) || (err == nil && config.ID == "") {
+			fmt.Printf("No configuration found for project: %s\n", projectName)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error fetching configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !deleteYes {
+			if !interactive() {
+				fmt.Println("Refusing to delete without confirmation. Pass --yes to skip the prompt.")
+				os.Exit(1)
+			}
+			confirmed := false
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("Delete configuration %s (%s)? This cannot be undone.", config.ProjectName, config.EnvironmentStage),
+			}
+			survey.AskOne(prompt, &confirmed)
+			if !confirmed {
+				fmt.Println("Aborted.")
+				return
+			}
+		}
+
+		err = client.DeleteConfiguration(cmd.Context(), projectName)
+		if errors.Is(err, api.ErrConflict) {
+			fmt.Printf("Cannot delete %s: %s\n", projectName, serverMessage(err))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error deleting configuration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Deleted configuration %s.\n", projectName)
+	},
+}
+
+// serverMessage returns the reason given by the portal for a failed request.
+func serverMessage(err error) string {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Message != "" {
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+func init() {
+	configCmd.AddCommand(configDeleteCmd)
+	configDeleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "delete without asking for confirmation")
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/configdiff"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/credentials"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// deployedSuffix selects the configuration of the last deployment made
+// with `grape deploy`, e.g. my-project@deployed.
+const deployedSuffix = "@deployed"
+
+var (
+	diffSideBySide      bool
+	diffJSON            bool
+	diffIncludeMetadata bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [a] [b]",
+	Short: "Show the differences between two configurations",
+	Long: `Diff compares two configurations field by field, including the nested
+full_config JSON. Each side is a project name, a configuration file (YAML or
+JSON) or <project>@deployed for the configuration last deployed from this
+machine with ` + "`grape deploy`" + `. Files are recognised by a path separator
+(./dev.yaml), a .yaml, .yml or .json extension, or a file: prefix
+(file:dev); anything else is a project name.
+
+Exit status is 0 when the configurations are the same, 1 when they differ and
+2 on errors, including invalid arguments and flags, so the command can gate CI
+pipelines.`,
+	Annotations: map[string]string{exitCodeAnnotation: "2"},
+	Args:        cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := loadDiffOperand(args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		b, err := loadDiffOperand(args[1])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+
+		opts := configdiff.Options{}
+		if !diffIncludeMetadata {
+			opts.Ignore = configdiff.MetadataFields
+		}
+		changes, err := configdiff.Compare(*a, *b, opts)
+		if err != nil {
+			fmt.Printf("Error comparing configurations: %v\n", err)
+			os.Exit(2)
+		}
+		changes = redactChanges(changes)
+
+		switch {
+		case diffJSON || structuredOutput():
+			if outputFormat == "" {
+				outputFormat = outputJSON
+			}
+			result := struct {
+				A       string              `json:"a"`
+				B       string              `json:"b"`
+				Equal   bool                `json:"equal"`
+				Changes []configdiff.Change `json:"changes"`
+			}{A: args[0], B: args[1], Equal: len(changes) == 0, Changes: changes}
+			if result.Changes == nil {
+				result.Changes = []configdiff.Change{}
+			}
+			if err := printStructured(result); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(2)
+			}
+		case len(changes) == 0:
+			fmt.Println("No differences.")
+		case diffSideBySide:
+			printSideBySide(args[0], args[1], changes)
+		default:
+			printUnified(args[0], args[1], changes)
+		}
+
+		if len(changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// loadDiffOperand resolves a project name, file or <project>@deployed. See
+// fileOperand for how files are told apart from projects.
+func loadDiffOperand(arg string) (*types.Configuration, error) {
+	if project, ok := strings.CutSuffix(arg, deployedSuffix); ok {
+		return loadDeployedSnapshot(project)
+	}
+	if path, ok := fileOperand(arg); ok {
+		return readConfigurationFile(path)
+	}
+
+	config, err := fetchConfiguration(arg)
+	if err != nil {
+		return nil, err
+	}
+	if config.ID == "" {
+		return nil, fmt.Errorf("no configuration found for project: %s", arg)
+	}
+	return config, nil
+}
+
+var (
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	diffPathStyle    = lipgloss.NewStyle().Bold(true)
+	diffMutedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+func printUnified(a, b string, changes []configdiff.Change) {
+	fmt.Print(renderUnified(a, b, changes))
+}
+
+// renderUnified formats changes like a unified diff, one line per side.
+func renderUnified(a, b string, changes []configdiff.Change) string {
+	var doc strings.Builder
+	line := func(style lipgloss.Style, s string) {
+		doc.WriteString(style.Render(s))
+		doc.WriteString("\n")
+	}
+
+	line(diffRemovedStyle, "--- "+a)
+	line(diffAddedStyle, "+++ "+b)
+	for _, c := range changes {
+		switch c.Kind {
+		case configdiff.Removed:
+			line(diffRemovedStyle, fmt.Sprintf("- %s: %s", c.Path, c.Old))
+		case configdiff.Added:
+			line(diffAddedStyle, fmt.Sprintf("+ %s: %s", c.Path, c.New))
+		default:
+			line(diffRemovedStyle, fmt.Sprintf("- %s: %s", c.Path, c.Old))
+			line(diffAddedStyle, fmt.Sprintf("+ %s: %s", c.Path, c.New))
+		}
+	}
+	line(diffMutedStyle, diffSummary(changes))
+	return doc.String()
+}
+
+func printSideBySide(a, b string, changes []configdiff.Change) {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 60 {
+		width = 120
+	}
+	pathWidth := width * 2 / 6
+	valueWidth := (width - pathWidth - 4) / 2
+
+	col := func(w int) lipgloss.Style { return lipgloss.NewStyle().Width(w).MaxWidth(w) }
+	row := func(path, left, right string, leftStyle, rightStyle lipgloss.Style) string {
+		return lipgloss.JoinHorizontal(lipgloss.Top,
+			col(pathWidth).Inherit(diffPathStyle).Render(path), "  ",
+			col(valueWidth).Inherit(leftStyle).Render(left), "  ",
+			col(valueWidth).Inherit(rightStyle).Render(right),
+		)
+	}
+
+	fmt.Println(row("FIELD", a, b, diffPathStyle, diffPathStyle))
+	for _, c := range changes {
+		switch c.Kind {
+		case configdiff.Removed:
+			fmt.Println(row(c.Path, c.Old, "(absent)", diffRemovedStyle, diffMutedStyle))
+		case configdiff.Added:
+			fmt.Println(row(c.Path, "(absent)", c.New, diffMutedStyle, diffAddedStyle))
+		default:
+			fmt.Println(row(c.Path, c.Old, c.New, diffRemovedStyle, diffAddedStyle))
+		}
+	}
+	fmt.Println(diffMutedStyle.Render(diffSummary(changes)))
+}
+
+func diffSummary(changes []configdiff.Change) string {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	noun := "fields differ"
+	if len(changes) == 1 {
+		noun = "field differs"
+	}
+	return fmt.Sprintf("%d %s (%d changed, %d added, %d removed)", len(changes), noun,
+		counts[configdiff.Changed], counts[configdiff.Added], counts[configdiff.Removed])
+}
+
+// deployedSnapshotPath is where `grape deploy` keeps the configuration it
+// last applied for a project in the active profile.
+func deployedSnapshotPath(projectName string) (string, error) {
+	dir, err := credentials.Dir()
+	if err != nil {
+		return "", fmt.Errorf("error getting config directory: %w", err)
+	}
+	profile := activeProfileName
+	if profile == "" {
+		profile = credentials.DefaultProfile
+	}
+	return filepath.Join(dir, "deployed", profile, filepath.Base(projectName)+".json"), nil
+}
+
+func saveDeployedSnapshot(config types.Configuration) error {
+	path, err := deployedSnapshotPath(config.ProjectName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func loadDeployedSnapshot(projectName string) (*types.Configuration, error) {
+	path, err := deployedSnapshotPath(projectName)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no deployment of %s recorded on this machine. Run `grape deploy %s` first", projectName, projectName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config types.Configuration
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func init() {
+	configCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffSideBySide, "side-by-side", false, "Show the two values next to each other instead of a unified diff")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Print the differences as JSON (same as -o json)")
+	diffCmd.Flags().BoolVar(&diffIncludeMetadata, "include-metadata", false, "Also compare id, user_id, timestamps and download counters")
+}
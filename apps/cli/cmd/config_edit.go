@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/configdiff"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfschema"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const editHeader = `# Editing %s. Lines starting with '#' are ignored.
+# Save and close the editor to apply the changes; leave the file unchanged to cancel.
+`
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit [project_name]",
+	Short: "Edit a configuration in $EDITOR",
+	Long: `Edit opens the configuration as YAML in $VISUAL or $EDITOR. When the editor
+exits, the file is validated against the template's variables and the changes
+are sent to the portal.
+
+If the configuration was changed on the portal while you were editing, the
+update is rejected and your edits are kept in a temporary file.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := args[0]
+		if !isTerminal(os.Stdin) {
+			fmt.Println("`grape config edit` needs an interactive terminal.")
+			os.Exit(1)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		original, err := client.GetConfigurationByProjectName(cmd.Context(), projectName)
+		if errors.Is(err, api.ErrNotFound) || (err == nil && original.ID == "") {
+			fmt.Printf("No configuration found for project: %s\n", projectName)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error fetching configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		content, err := editableYAML(*original)
+		if err != nil {
+			fmt.Printf("Error encoding configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		tmp, err := os.CreateTemp("", "grape-"+projectName+"-*.yaml")
+		if err != nil {
+			fmt.Printf("Error creating temporary file: %v\n", err)
+			os.Exit(1)
+		}
+		path := tmp.Name()
+		header := fmt.Sprintf(editHeader, projectName)
+		_, err = tmp.WriteString(header + string(content))
+		tmp.Close()
+		if err != nil {
+			fmt.Printf("Error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+
+		var edited *types.Configuration
+		for {
+			if err := runEditor(path); err != nil {
+				fmt.Printf("Error running editor: %v\n", err)
+				fmt.Printf("Your changes are in %s\n", path)
+				os.Exit(1)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Printf("Error reading %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			if bytes.Equal(bytes.TrimPrefix(data, []byte(header)), content) {
+				os.Remove(path)
+				fmt.Println("No changes.")
+				return
+			}
+
+			edited, err = readConfigurationFile(path)
+			if err == nil && edited.ProjectName != original.ProjectName {
+				err = fmt.Errorf("project_name cannot be changed (was %s, now %s)", original.ProjectName, edited.ProjectName)
+			}
+			var problems []tfschema.Problem
+			if err == nil {
+				problems, err = validateConfiguration(*edited)
+			}
+			if err != nil {
+				fmt.Println(err)
+			} else {
+				printProblems(problems, false)
+				if !tfschema.HasErrors(problems) {
+					break
+				}
+			}
+
+			again := true
+			survey.AskOne(&survey.Confirm{Message: "Edit again?", Default: true}, &again)
+			if !again {
+				fmt.Printf("Aborted. Your changes are in %s\n", path)
+				os.Exit(1)
+			}
+		}
+
+		changes, err := configdiff.Compare(*original, *edited, configdiff.Options{Ignore: configdiff.MetadataFields})
+		if err != nil {
+			fmt.Printf("Error comparing configurations: %v\n", err)
+			os.Exit(1)
+		}
+		if len(changes) == 0 {
+			os.Remove(path)
+			fmt.Println("No changes.")
+			return
+		}
+		printUnified(projectName, path, changes)
+
+		_, err = client.UpdateConfiguration(cmd.Context(), projectName, *edited, original.UpdatedAt)
+		if errors.Is(err, api.ErrConflict) {
+			fmt.Printf("%s was changed on the portal while you were editing, nothing was saved.\n", projectName)
+			fmt.Printf("Your changes are in %s\n", path)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error updating configuration: %v\n", err)
+			fmt.Printf("Your changes are in %s\n", path)
+			os.Exit(1)
+		}
+		os.Remove(path)
+		fmt.Printf("✓ Updated configuration %s.\n", projectName)
+	},
+}
+
+// editableYAML renders a configuration for editing, without the fields the
+// portal maintains.
+func editableYAML(config types.Configuration) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	doc := node.Content[0]
+	content := doc.Content[:0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if slices.Contains(configdiff.MetadataFields, doc.Content[i].Value) {
+			continue
+		}
+		content = append(content, doc.Content[i], doc.Content[i+1])
+	}
+	doc.Content = content
+
+	plainStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// runEditor opens path in $VISUAL, $EDITOR or vi.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The variable may carry arguments, e.g. "code --wait".
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfvars"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportDir    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [project_name]",
+	Short: "Write Terraform variable and backend files for a configuration",
+	Long: `Export renders the configuration into the variables of the infrastructure
+template and writes them to --dir together with backend.tfvars, whose state
+bucket and key are derived from the project, environment and region.
+
+Formats:
+  tfvars  terraform.tfvars and backend.tfvars
+  yaml    terraform.tfvars.yaml and backend.tfvars
+  zip     <project>-<environment>.zip containing the tfvars files
+
+Exporting an unchanged configuration produces identical files.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := args[0]
+
+		switch exportFormat {
+		case tfvars.FormatTFVars, tfvars.FormatYAML, tfvars.FormatZip:
+		default:
+			fmt.Printf("Unknown format %q (expected tfvars, yaml or zip)\n", exportFormat)
+			os.Exit(1)
+		}
+
+		config, err := fetchConfiguration(projectName)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if config.ID == "" {
+			fmt.Printf("No configuration found for project: %s\n", projectName)
+			os.Exit(1)
+		}
+
+		files, err := tfvars.Bundle(*config, exportFormat)
+		if err != nil {
+			fmt.Printf("Error rendering configuration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := os.MkdirAll(exportDir, 0755); err != nil {
+			fmt.Printf("Error creating %s: %v\n", exportDir, err)
+			os.Exit(1)
+		}
+		for _, f := range files {
+			path := filepath.Join(exportDir, f.Name)
+			if err := os.WriteFile(path, f.Data, 0644); err != nil {
+				fmt.Printf("Error writing %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			fmt.Printf("Wrote %s\n", path)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", tfvars.FormatTFVars, "Output format: tfvars, yaml or zip")
+	exportCmd.Flags().StringVar(&exportDir, "dir", ".", "Directory to write the files to")
+}
@@ -1,84 +1,153 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
 	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
-	"github.com/imroc/req/v3"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
+const (
+	// listPageSize is how many rows the TUI loads at a time.
+	listPageSize = 50
+	// listPrefetchRows is how close to the last loaded row the cursor gets
+	// before the next page is requested.
+	listPrefetchRows = 5
+	// listSearchDelay debounces server searches while typing a filter.
+	listSearchDelay = 300 * time.Millisecond
+)
+
+var (
+	listStage        string
+	listPlatform     string
+	listUpdatedSince string
+	listSearch       string
+	listLimit        int
+	listPage         int
+)
+
+// listColumns are the TUI columns and the fields they sort by.
+var listColumns = []struct {
+	title string
+	field string
+}{
+	{"Project", "project_name"},
+	{"Environment", "environment_stage"},
+	{"Container Platform", "container_platform"},
+	{"Updated At", "updated_at"},
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all configurations",
+	Long: `List shows your configurations. The filters are applied by the portal.
+
+In the interactive view, '/' filters the rows as you type, 1-4 sort by a
+column (press again to reverse) and further pages load as you scroll. 'r'
+reloads the list. --page only applies to the printed table and to -o json/yaml;
+the interactive view always starts at the first page.
+
+With --watch the list follows changes made on the portal: it subscribes to
+the portal's change stream, or polls every --interval where the stream is not
+available, and marks rows that changed.`,
+	Example: `  grape config list --stage production --updated-since 7d
+  grape config list --search shop -o json
+  grape config list --limit 20 --page 2 -o table`,
 	Run: func(cmd *cobra.Command, args []string) {
-		token, err := getAuthToken()
+		opts, err := listOptions()
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 
-		webOrigin := os.Getenv("GRAPE_WEB_ORIGIN")
-		if webOrigin == "" {
-			webOrigin = "https://localhost:3000"
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
-		listURL := fmt.Sprintf("%s/api/cli/configurations", webOrigin)
 
-		client := req.C()
-		var result struct {
-			Configurations []types.ConfigurationSummary `json:"configurations"`
+		tui := interactive()
+		if listWatch && !tui {
+			fmt.Println("--watch needs an interactive terminal.")
+			os.Exit(1)
+		}
+		if listInterval < time.Second {
+			fmt.Println("--interval must be at least 1s.")
+			os.Exit(1)
 		}
-		var errMsg struct {
-			Error string `json:"error"`
+		if tui {
+			// The interactive view starts at the top and loads further
+			// pages as you scroll, so --page only applies to the printed
+			// table.
+			opts.Page = 0
+			if opts.Limit == 0 {
+				opts.Limit = listPageSize
+			}
 		}
 
-		resp, err := client.R().
-			SetBearerAuthToken(token).
-			SetSuccessResult(&result).
-			SetErrorResult(&errMsg).
-			Get(listURL)
-
+		page, err := client.ListConfigurations(cmd.Context(), opts)
 		if err != nil {
-			fmt.Printf("Error connecting to server: %v\n", err)
+			fmt.Printf("Error fetching configurations: %v\n", err)
 			os.Exit(1)
 		}
+		configurations := page.Configurations
 
-		if resp.IsErrorState() {
-			fmt.Printf("Error fetching configurations (HTTP %d): %s\n", resp.StatusCode, errMsg.Error)
-			os.Exit(1)
+		if structuredOutput() {
+			if configurations == nil {
+				configurations = []types.ConfigurationSummary{}
+			}
+			if err := printStructured(configurations); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+			return
 		}
 
-		if len(result.Configurations) == 0 {
+		// With --watch, wait for configurations to appear.
+		if len(configurations) == 0 && !listWatch {
 			fmt.Println("No configurations found.")
 			return
 		}
 
+		if !tui {
+			printConfigurationTable(configurations)
+			if opts.Limit > 0 && page.Total > len(configurations) {
+				fmt.Fprintf(os.Stderr, "Page %d of %d (%d configurations). Use --page to see more.\n",
+					max(opts.Page, 1), (page.Total+opts.Limit-1)/opts.Limit, page.Total)
+			}
+			return
+		}
+
 		width, height, err := term.GetSize(int(os.Stdout.Fd()))
 		if err != nil {
 			height = 20 // Default height
 		}
 		tableHeight := int(float64(height) * 0.8)
 
-		columns := []table.Column{
-			{Title: "Project", Width: width / 5},
-			{Title: "Environment", Width: width / 6},
-			{Title: "Container Platform", Width: width / 5},
-			{Title: "Updated At", Width: width / 6},
+		widths := []int{width / 5, width / 6, width / 5, width / 6}
+		columns := make([]table.Column, len(listColumns))
+		for i, c := range listColumns {
+			columns[i] = table.Column{Title: c.title, Width: widths[i]}
 		}
 
-		rows := createRows(result.Configurations)
-
 		t := table.New(
 			table.WithColumns(columns),
-			table.WithRows(rows),
 			table.WithFocused(true),
 			table.WithHeight(tableHeight),
 		)
@@ -98,54 +167,454 @@ var listCmd = &cobra.Command{
 
 		t.SetStyles(s)
 
-		m := listModel{table: t, originalRows: rows, configurations: result.Configurations}
-		if _, err := tea.NewProgram(m).Run(); err != nil {
+		filter := textinput.New()
+		filter.Prompt = "/"
+		filter.Placeholder = "filter"
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		m := listModel{
+			table:          t,
+			filter:         filter,
+			ctx:            ctx,
+			client:         client,
+			query:          opts,
+			configurations: configurations,
+			total:          page.Total,
+			page:           1,
+			searched:       opts.Search,
+			cache:          map[string]*types.Configuration{},
+			viewport:       viewport.New(width-2, tableHeight),
+			watch:          listWatch,
+			interval:       listInterval,
+			changed:        map[string]time.Time{},
+		}
+		m.refreshRows()
+		final, err := tea.NewProgram(m).Run()
+		// Stop requests still in flight and close the event stream.
+		cancel()
+		if final, ok := final.(listModel); ok && final.stream != nil {
+			final.stream.Close()
+		}
+		if err != nil {
 			fmt.Println("Error running program:", err)
 			os.Exit(1)
 		}
 	},
 }
 
+// listOptions turns the list flags into API options.
+func listOptions() (api.ListOptions, error) {
+	stage, err := normalizeStage(listStage)
+	if err != nil {
+		return api.ListOptions{}, err
+	}
+	opts := api.ListOptions{
+		Stage:    stage,
+		Platform: listPlatform,
+		Search:   listSearch,
+		Limit:    listLimit,
+		Page:     listPage,
+	}
+	if listLimit < 0 || listPage < 0 {
+		return opts, fmt.Errorf("--limit and --page must be positive")
+	}
+	if listPage > 1 && listLimit == 0 {
+		return opts, fmt.Errorf("--page needs --limit")
+	}
+	if listUpdatedSince != "" {
+		if opts.UpdatedSince, err = parseSince(listUpdatedSince, time.Now()); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+var daysPattern = regexp.MustCompile(`^(\d+)d$`)
+
+// parseSince accepts a date (2006-01-02), an RFC 3339 timestamp or an age
+// such as 7d or 36h, which is counted back from now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if m := daysPattern.FindStringSubmatch(s); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		return now.AddDate(0, 0, -days), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --updated-since %q (expected a date like 2026-01-31 or an age like 7d or 12h)", s)
+}
+
+// pageMsg delivers a page requested by the list TUI. seq ties it to the
+// request, so pages of a superseded query are dropped.
+type pageMsg struct {
+	seq    int
+	search string
+	page   *api.ConfigurationPage
+	err    error
+}
+
+// searchMsg fires when the filter has not changed for listSearchDelay.
+type searchMsg struct {
+	seq int
+}
+
 type listModel struct {
-	table          table.Model
-	originalRows   []table.Row
+	table  table.Model
+	filter textinput.Model
+
+	ctx    context.Context
+	client *api.Client
+	// query holds the filters from the command line.
+	query api.ListOptions
+
+	// configurations are the rows loaded so far, in server order; visible
+	// are the ones shown, in table order.
 	configurations []types.ConfigurationSummary
-	sortAsc        bool
+	visible        []types.ConfigurationSummary
+	total          int
+	page           int
+	// searched is the server-side search the loaded rows match.
+	searched string
+	loading  bool
+	seq      int
+	err      error
+
+	sortColumn int
+	sortDesc   bool
+
+	// cache holds the full configurations fetched for the detail pane.
+	cache map[string]*types.Configuration
+	// detailTitle is set while the detail pane is open. detail is the
+	// configuration shown, or nil for a diff.
+	detailTitle string
+	detail      *types.Configuration
+	viewport    viewport.Model
+	// diffBase is the project marked with 'd' to compare with the next.
+	diffBase string
+	notice   string
+
+	// State of --watch.
+	watch        bool
+	interval     time.Duration
+	stream       *api.EventStream
+	refreshing   bool
+	refreshAgain bool
+	refreshedAt  time.Time
+	refreshErr   error
+	retryAt      time.Time
+	failures     int
+	pollGen      int
+	// changed holds the IDs of rows that changed and when.
+	changed map[string]time.Time
 }
 
-func (m listModel) Init() tea.Cmd { return nil }
+func (m listModel) Init() tea.Cmd {
+	if m.watch {
+		return tea.Batch(clock(), m.connect())
+	}
+	return nil
+}
+
+// complete reports whether every matching configuration is loaded, so
+// sorting and filtering can happen locally.
+func (m listModel) complete() bool {
+	return len(m.configurations) >= m.total
+}
+
+// load requests a page. With reset the loaded rows are replaced, for a new
+// sort order or search.
+func (m *listModel) load(reset bool) tea.Cmd {
+	m.seq++
+	m.loading = true
+	if reset {
+		m.page = 0
+	}
+
+	opts := m.query
+	opts.Page = m.page + 1
+	if opts.Limit == 0 {
+		opts.Limit = listPageSize
+	}
+	opts.Sort = listColumns[m.sortColumn].field
+	opts.Descending = m.sortDesc
+	if search := strings.TrimSpace(m.filter.Value()); search != "" {
+		opts.Search = search
+	}
+
+	seq, ctx, client := m.seq, m.ctx, m.client
+	return func() tea.Msg {
+		page, err := client.ListConfigurations(ctx, opts)
+		return pageMsg{seq: seq, search: opts.Search, page: page, err: err}
+	}
+}
+
+// refreshRows sorts and filters the loaded configurations into the table.
+func (m *listModel) refreshRows() {
+	configs := make([]types.ConfigurationSummary, 0, len(m.configurations))
+	filter := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	for _, c := range m.configurations {
+		if filter == "" || filter == strings.ToLower(m.searched) || matchesFilter(c, filter) {
+			configs = append(configs, c)
+		}
+	}
+
+	field := listColumns[m.sortColumn].field
+	sort.SliceStable(configs, func(i, j int) bool {
+		if m.sortDesc {
+			return lessBy(field, configs[j], configs[i])
+		}
+		return lessBy(field, configs[i], configs[j])
+	})
+
+	rows := createRows(configs)
+	for i, c := range configs {
+		if _, ok := m.changed[c.ID]; ok {
+			rows[i][0] = changedMarker + rows[i][0]
+		}
+	}
+	m.visible = configs
+	m.table.SetRows(rows)
+
+	columns := m.table.Columns()
+	for i, c := range listColumns {
+		columns[i].Title = c.title
+		if i == m.sortColumn && m.sortDesc {
+			columns[i].Title += " ▼"
+		} else if i == m.sortColumn {
+			columns[i].Title += " ▲"
+		}
+	}
+	m.table.SetColumns(columns)
+}
+
+func matchesFilter(c types.ConfigurationSummary, filter string) bool {
+	for _, s := range []string{c.ProjectName, c.EnvironmentStage, c.ContainerPlatform} {
+		if strings.Contains(strings.ToLower(s), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func lessBy(field string, a, b types.ConfigurationSummary) bool {
+	switch field {
+	case "environment_stage":
+		return a.EnvironmentStage < b.EnvironmentStage
+	case "container_platform":
+		return a.ContainerPlatform < b.ContainerPlatform
+	case "updated_at":
+		return a.UpdatedAt.Before(b.UpdatedAt)
+	}
+	return a.ProjectName < b.ProjectName
+}
 
 func (m listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if model, cmd, handled := m.updateWatch(msg); handled {
+		return model, cmd
+	}
+
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
+	case pageMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.err = nil
+		if m.page == 0 {
+			m.configurations = nil
+			m.table.SetCursor(0)
+		}
+		m.configurations = append(m.configurations, msg.page.Configurations...)
+		m.total = msg.page.Total
+		if len(msg.page.Configurations) == 0 {
+			// Nothing more to fetch, whatever the total said.
+			m.total = len(m.configurations)
+		}
+		m.page++
+		m.searched = msg.search
+		m.refreshRows()
+		return m, nil
+
+	case searchMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+		return m, m.load(true)
+
+	case detailMsg:
+		if msg.err != nil {
+			m.notice = msg.err.Error()
+			return m, nil
+		}
+		m.notice = ""
+		for _, config := range msg.configs {
+			m.cache[config.ProjectName] = config
+		}
+		if len(msg.configs) == 2 {
+			if err := m.showDiff(msg.configs[0], msg.configs[1]); err != nil {
+				m.notice = err.Error()
+			}
+			return m, nil
+		}
+		m.showDetail(msg.configs[0])
+		return m, nil
+
+	case editedMsg:
+		delete(m.cache, msg.projectName)
+		m.notice = ""
+		if msg.err != nil {
+			m.notice = "grape config edit: " + msg.err.Error()
+		}
+		cmds := []tea.Cmd{m.load(true)}
+		if m.detail != nil && m.detail.ProjectName == msg.projectName {
+			cmds = append(cmds, m.fetchDetail(msg.projectName))
+		}
+		return m, tea.Batch(cmds...)
+
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width - 2
+		m.viewport.Height = int(float64(msg.Height) * 0.8)
+		m.table.SetHeight(m.viewport.Height)
+
 	case tea.KeyMsg:
+		if m.inDetail() {
+			switch msg.String() {
+			case "esc", "backspace", "q":
+				m.detailTitle, m.detail = "", nil
+				if m.diffBase == "" {
+					m.notice = ""
+				}
+				return m, nil
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			if m.detail != nil {
+				if model, cmd, handled := m.updateDetail(msg); handled {
+					return model, cmd
+				}
+			}
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+
+		if m.filter.Focused() {
+			switch msg.String() {
+			case "enter", "esc":
+				m.filter.Blur()
+				m.table.Focus()
+				if msg.String() == "esc" {
+					m.filter.SetValue("")
+					m.refreshRows()
+					return m, m.searchLater()
+				}
+				return m, nil
+			case "ctrl+c":
+				return m, tea.Quit
+			}
+			m.filter, cmd = m.filter.Update(msg)
+			m.refreshRows()
+			return m, tea.Batch(cmd, m.searchLater())
+		}
+
+		if m.diffBase == "" {
+			m.notice = ""
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "/":
+			m.table.Blur()
+			return m, m.filter.Focus()
+		case "r":
+			return m, m.refresh()
+		case "1", "2", "3", "4":
+			column := int(msg.String()[0] - '1')
+			if column == m.sortColumn {
+				m.sortDesc = !m.sortDesc
+			} else {
+				m.sortColumn, m.sortDesc = column, false
+			}
+			m.refreshRows()
+			if !m.complete() {
+				return m, m.load(true)
+			}
+			return m, nil
 		case "s":
-			m.sortAsc = !m.sortAsc
-			sort.Slice(m.configurations, func(i, j int) bool {
-				if m.sortAsc {
-					return m.configurations[i].ProjectName < m.configurations[j].ProjectName
-				}
-				return m.configurations[i].ProjectName > m.configurations[j].ProjectName
-			})
-			m.table.SetRows(createRows(m.configurations))
+			// Kept from before sorting on any column: toggle project order.
+			m.sortDesc = m.sortColumn == 0 && !m.sortDesc
+			m.sortColumn = 0
+			m.refreshRows()
+			if !m.complete() {
+				return m, m.load(true)
+			}
 			return m, nil
 		}
+		if model, cmd, handled := m.updateDetail(msg); handled {
+			return model, cmd
+		}
 	}
+
 	m.table, cmd = m.table.Update(msg)
+	if !m.loading && !m.complete() && m.table.Cursor() >= len(m.table.Rows())-listPrefetchRows {
+		return m, tea.Batch(cmd, m.load(false))
+	}
 	return m, cmd
 }
 
+// searchLater schedules a server search for the current filter once typing
+// pauses. Local filtering is enough when everything is loaded.
+func (m *listModel) searchLater() tea.Cmd {
+	if m.complete() && m.searched == m.query.Search {
+		return nil
+	}
+	m.seq++
+	seq := m.seq
+	return tea.Tick(listSearchDelay, func(time.Time) tea.Msg { return searchMsg{seq: seq} })
+}
+
 var baseStyle = lipgloss.NewStyle().
 	BorderStyle(lipgloss.RoundedBorder()).
 	BorderForeground(lipgloss.Color("240"))
 
 func (m listModel) View() string {
-	status := fmt.Sprintf("Showing %d configurations | Press 'q' to quit | 'j/k' or arrows to navigate | 's' to sort by Project", len(m.table.Rows()))
+	if m.inDetail() {
+		return m.detailView()
+	}
 	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1)
-	return baseStyle.Render(m.table.View()) + "\n" + statusStyle.Render(status)
+
+	count := fmt.Sprintf("Showing %d configurations", len(m.table.Rows()))
+	if !m.complete() {
+		count = fmt.Sprintf("Showing %d of %d configurations", len(m.table.Rows()), m.total)
+	}
+	status := count
+	if m.watch {
+		status += " | " + m.watchStatus()
+	}
+	switch {
+	case m.notice != "":
+		status += " | " + m.notice
+	case m.loading:
+		status += " | Loading…"
+	case m.err != nil:
+		status += " | " + m.err.Error()
+	}
+	help := "Press 'q' to quit | 'j/k' or arrows to navigate | 'enter' for details | '/' to filter | '1-4' to sort | 'r' to reload"
+
+	view := baseStyle.Render(m.table.View()) + "\n"
+	if m.filter.Focused() || m.filter.Value() != "" {
+		view += " " + m.filter.View() + "\n"
+	}
+	return view + statusStyle.Render(status) + "\n" + statusStyle.Render(help)
 }
 
 func createRows(configs []types.ConfigurationSummary) []table.Row {
@@ -161,6 +630,23 @@ func createRows(configs []types.ConfigurationSummary) []table.Row {
 	return rows
 }
 
+// printConfigurationTable writes the list as a plain table for scripts and
+// non-terminal output.
+func printConfigurationTable(configs []types.ConfigurationSummary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tENVIRONMENT\tCONTAINER PLATFORM\tUPDATED AT\tID")
+	for _, config := range configs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			config.ProjectName,
+			config.EnvironmentStage,
+			config.ContainerPlatform,
+			config.UpdatedAt.Format(time.RFC3339),
+			config.ID,
+		)
+	}
+	w.Flush()
+}
+
 func formatTime(t time.Time) string {
 	if time.Since(t).Hours() < 24*7 {
 		return humanize.Time(t)
@@ -170,4 +656,12 @@ func formatTime(t time.Time) string {
 
 func init() {
 	configCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listStage, "stage", "", "only show this environment stage (development, staging, production)")
+	listCmd.Flags().StringVar(&listPlatform, "platform", "", "only show this container platform")
+	listCmd.Flags().StringVar(&listUpdatedSince, "updated-since", "", "only show configurations updated since a date (2026-01-31) or age (7d, 12h)")
+	listCmd.Flags().StringVar(&listSearch, "search", "", "only show configurations whose name, description, stage or platform contains this text")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "page size (default: all configurations, or 50 at a time in the interactive view)")
+	listCmd.Flags().IntVar(&listPage, "page", 1, "page to print, starting at 1 (requires --limit; ignored by the interactive view)")
+	listCmd.Flags().BoolVarP(&listWatch, "watch", "w", false, "keep the list up to date and mark changed rows")
+	listCmd.Flags().DurationVar(&listInterval, "interval", 10*time.Second, "how often --watch polls when the portal cannot push changes")
 }
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/configdiff"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pkg/browser"
+)
+
+// The list TUI's detail pane shows a configuration or the diff of two. The
+// full configurations are fetched on demand and kept in listModel.cache for
+// the rest of the session.
+
+// detailMsg delivers the configurations needed for the detail pane: one to
+// show, or two to compare.
+type detailMsg struct {
+	configs []*types.Configuration
+	err     error
+}
+
+// editedMsg reports that `grape config edit` exited.
+type editedMsg struct {
+	projectName string
+	err         error
+}
+
+var detailTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63")).Padding(0, 1)
+
+// fetchDetail loads the named configurations, from the cache when possible.
+// It is called from Update: the cache is read here and only the missing
+// configurations are fetched in the background, since Update writes the
+// cache while commands run.
+func (m listModel) fetchDetail(names ...string) tea.Cmd {
+	configs := make([]*types.Configuration, len(names))
+	for i, name := range names {
+		configs[i] = m.cache[name]
+	}
+	ctx, client := m.ctx, m.client
+	return func() tea.Msg {
+		for i, name := range names {
+			if configs[i] != nil {
+				continue
+			}
+			config, err := client.GetConfigurationByProjectName(ctx, name)
+			if err != nil {
+				return detailMsg{err: fmt.Errorf("error fetching %s: %w", name, err)}
+			}
+			configs[i] = config
+		}
+		return detailMsg{configs: configs}
+	}
+}
+
+// target is the project the detail keys act on: the one in the detail pane,
+// or else the selected row.
+func (m listModel) target() string {
+	if m.detail != nil {
+		return m.detail.ProjectName
+	}
+	if i := m.table.Cursor(); i >= 0 && i < len(m.visible) {
+		return m.visible[i].ProjectName
+	}
+	return ""
+}
+
+// targetID returns the ID of the target project.
+func (m listModel) targetID() string {
+	if m.detail != nil {
+		return m.detail.ID
+	}
+	if i := m.table.Cursor(); i >= 0 && i < len(m.visible) {
+		return m.visible[i].ID
+	}
+	return ""
+}
+
+func (m *listModel) showDetail(config *types.Configuration) {
+	m.detail = config
+	m.detailTitle = config.ProjectName
+	m.viewport.SetContent(renderConfiguration(*config, renderOptions{reveal: revealSecrets}))
+	m.viewport.GotoTop()
+}
+
+func (m *listModel) showDiff(a, b *types.Configuration) error {
+	changes, err := configdiff.Compare(*a, *b, configdiff.Options{Ignore: configdiff.MetadataFields})
+	if err != nil {
+		return err
+	}
+	content := "No differences.\n"
+	if len(changes) > 0 {
+		content = renderUnified(a.ProjectName, b.ProjectName, redactChanges(changes))
+	}
+	m.detail = nil
+	m.detailTitle = a.ProjectName + " ↔ " + b.ProjectName
+	m.viewport.SetContent(content)
+	m.viewport.GotoTop()
+	return nil
+}
+
+// updateDetail handles the detail keys, which work on the table and in the
+// detail pane. handled is false for keys it does not know.
+func (m listModel) updateDetail(msg tea.KeyMsg) (_ tea.Model, _ tea.Cmd, handled bool) {
+	name := m.target()
+	if name == "" {
+		return m, nil, false
+	}
+
+	switch msg.String() {
+	case "enter":
+		if m.inDetail() {
+			return m, nil, false
+		}
+		m.notice = "Loading " + name + "…"
+		return m, m.fetchDetail(name), true
+
+	case "o":
+		id := m.targetID()
+		if id == "" {
+			return m, nil, true
+		}
+		if err := browser.OpenURL(configurationURL(id)); err != nil {
+			m.notice = "Error opening browser: " + err.Error()
+		} else {
+			m.notice = "Opened " + name + " in the browser"
+		}
+		return m, nil, true
+
+	case "y":
+		id := m.targetID()
+		if id == "" {
+			return m, nil, true
+		}
+		if err := clipboard.WriteAll(id); err != nil {
+			// No clipboard tool, e.g. over SSH: ask the terminal instead.
+			osc52.New(id).WriteTo(os.Stderr)
+		}
+		m.notice = "Copied ID " + id
+		return m, nil, true
+
+	case "e":
+		exe, err := os.Executable()
+		if err != nil {
+			m.notice = err.Error()
+			return m, nil, true
+		}
+		// The editor runs as this command would, with the same profile and
+		// token; GRAPE_TOKEN and GRAPE_PROFILE are inherited.
+		args := []string{"config", "edit", name}
+		if activeProfileName != "" {
+			args = append(args, "--profile", activeProfileName)
+		}
+		if tokenFile != "" {
+			args = append(args, "--token-file", tokenFile)
+		}
+		return m, tea.ExecProcess(exec.Command(exe, args...), func(err error) tea.Msg {
+			return editedMsg{projectName: name, err: err}
+		}), true
+
+	case "d":
+		switch m.diffBase {
+		case "":
+			m.diffBase = name
+			m.notice = "Comparing with " + name + ": select another configuration and press 'd'"
+			return m, nil, true
+		case name:
+			m.diffBase = ""
+			m.notice = ""
+			return m, nil, true
+		}
+		base := m.diffBase
+		m.diffBase = ""
+		m.notice = "Comparing " + base + " with " + name + "…"
+		return m, m.fetchDetail(base, name), true
+	}
+	return m, nil, false
+}
+
+func (m listModel) inDetail() bool {
+	return m.detailTitle != ""
+}
+
+func (m listModel) detailView() string {
+	keys := "'esc' back | 'o' open in browser | 'e' edit | 'd' diff | 'y' copy ID"
+	if m.detail == nil {
+		keys = "'esc' back"
+	}
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1)
+	status := keys
+	if m.notice != "" {
+		status = m.notice + " | " + keys
+	}
+	return detailTitleStyle.Render(m.detailTitle) + "\n" +
+		baseStyle.Render(m.viewport.View()) + "\n" +
+		statusStyle.Render(status)
+}
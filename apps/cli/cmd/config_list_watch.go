@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+// With --watch the list TUI stays current: the portal's event stream
+// triggers a refresh whenever a configuration changes, and without a stream
+// the list is polled. Errors back off exponentially up to watchMaxBackoff.
+// Rows that changed are marked for a while.
+
+const (
+	changedMarker = "● "
+	// changedHighlight is how long a changed row stays marked.
+	changedHighlight = 30 * time.Second
+	// watchMaxBackoff caps the delay between attempts after errors. It is
+	// also the poll interval while the event stream is connected.
+	watchMaxBackoff = 5 * time.Minute
+)
+
+var (
+	listWatch    bool
+	listInterval time.Duration
+)
+
+// refreshMsg delivers the reloaded rows. seq is the listModel.seq the
+// refresh started with; rows of an outdated query are dropped.
+type refreshMsg struct {
+	seq            int
+	configurations []types.ConfigurationSummary
+	total          int
+	err            error
+}
+
+// pollMsg triggers a refresh; every --interval without an event stream and
+// rarely with one. Only the latest scheduled poll, matching
+// listModel.pollGen, counts.
+type pollMsg struct {
+	gen int
+}
+
+// clockMsg ticks every second to update the refresh indicator and expire
+// row highlights.
+type clockMsg time.Time
+
+type streamMsg struct {
+	stream *api.EventStream
+	err    error
+}
+
+type streamEventMsg struct {
+	stream *api.EventStream
+}
+
+type streamClosedMsg struct {
+	stream *api.EventStream
+	err    error
+}
+
+type reconnectMsg struct{}
+
+func clock() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return clockMsg(t) })
+}
+
+// connect opens the event stream.
+func (m listModel) connect() tea.Cmd {
+	ctx, client := m.ctx, m.client
+	return func() tea.Msg {
+		stream, err := client.ConfigurationEvents(ctx)
+		return streamMsg{stream: stream, err: err}
+	}
+}
+
+// next waits for the next event on stream.
+func next(stream *api.EventStream) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := stream.Next(); err != nil {
+			return streamClosedMsg{stream: stream, err: err}
+		}
+		return streamEventMsg{stream: stream}
+	}
+}
+
+// refresh reloads every loaded page. A refresh requested while one is
+// running is done once the current one finishes.
+func (m *listModel) refresh() tea.Cmd {
+	if m.refreshing {
+		m.refreshAgain = true
+		return nil
+	}
+	m.refreshing = true
+
+	opts := m.query
+	if opts.Limit == 0 {
+		opts.Limit = listPageSize
+	}
+	opts.Sort = listColumns[m.sortColumn].field
+	opts.Descending = m.sortDesc
+	if m.searched != "" {
+		opts.Search = m.searched
+	}
+	first, last := max(m.query.Page, 1), max(m.page, 1)
+
+	seq, ctx, client := m.seq, m.ctx, m.client
+	return func() tea.Msg {
+		msg := refreshMsg{seq: seq}
+		for page := first; page <= last; page++ {
+			opts.Page = page
+			result, err := client.ListConfigurations(ctx, opts)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			msg.configurations = append(msg.configurations, result.Configurations...)
+			msg.total = result.Total
+			if len(result.Configurations) < opts.Limit {
+				break
+			}
+		}
+		return msg
+	}
+}
+
+// schedulePoll polls after delay, replacing any poll scheduled before.
+func (m *listModel) schedulePoll(delay time.Duration) tea.Cmd {
+	m.pollGen++
+	gen := m.pollGen
+	m.retryAt = time.Now().Add(delay)
+	return tea.Tick(delay, func(time.Time) tea.Msg { return pollMsg{gen: gen} })
+}
+
+// delay is the poll interval, doubled for every consecutive failure.
+func (m listModel) delay() time.Duration {
+	d := m.interval
+	for i := 0; i < m.failures && d < watchMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, watchMaxBackoff)
+}
+
+// applyRefresh replaces the loaded rows, marking the ones that are new or
+// were updated since the last load.
+func (m *listModel) applyRefresh(configs []types.ConfigurationSummary, total int) tea.Cmd {
+	previous := make(map[string]time.Time, len(m.configurations))
+	for _, c := range m.configurations {
+		previous[c.ID] = c.UpdatedAt
+	}
+	selected := m.targetID()
+
+	var cmd tea.Cmd
+	now := time.Now()
+	for _, c := range configs {
+		if at, ok := previous[c.ID]; ok && at.Equal(c.UpdatedAt) {
+			continue
+		}
+		if m.watch {
+			m.changed[c.ID] = now
+		}
+		delete(m.cache, c.ProjectName)
+		if m.detail != nil && m.detail.ID == c.ID {
+			cmd = m.fetchDetail(c.ProjectName)
+		}
+	}
+
+	m.configurations = configs
+	m.total = max(total, len(configs))
+	m.refreshRows()
+
+	// Keep the cursor on the same configuration when rows move.
+	for i, c := range m.visible {
+		if c.ID == selected {
+			m.table.SetCursor(i)
+			break
+		}
+	}
+	return cmd
+}
+
+// updateWatch handles the messages of --watch and of refreshing with 'r'.
+// handled is false for other messages.
+func (m listModel) updateWatch(msg tea.Msg) (_ tea.Model, _ tea.Cmd, handled bool) {
+	switch msg := msg.(type) {
+	case clockMsg:
+		expired := false
+		for id, at := range m.changed {
+			if time.Since(at) > changedHighlight {
+				delete(m.changed, id)
+				expired = true
+			}
+		}
+		if expired {
+			m.refreshRows()
+		}
+		return m, clock(), true
+
+	case streamMsg:
+		if errors.Is(msg.err, api.ErrNotFound) {
+			// The portal has no event stream; poll instead.
+			return m, m.schedulePoll(m.interval), true
+		}
+		if msg.err != nil {
+			m.failures++
+			m.refreshErr = msg.err
+			return m, tea.Batch(m.schedulePoll(m.delay()), tea.Tick(m.delay(), func(time.Time) tea.Msg { return reconnectMsg{} })), true
+		}
+		if m.stream != nil && m.stream != msg.stream {
+			m.stream.Close()
+		}
+		m.stream = msg.stream
+		m.failures = 0
+		// Catch up on changes made while disconnected.
+		return m, tea.Batch(next(m.stream), m.refresh()), true
+
+	case streamEventMsg:
+		if msg.stream != m.stream {
+			msg.stream.Close()
+			return m, nil, true
+		}
+		return m, tea.Batch(next(m.stream), m.refresh()), true
+
+	case streamClosedMsg:
+		msg.stream.Close()
+		if msg.stream != m.stream {
+			return m, nil, true
+		}
+		m.stream = nil
+		m.failures++
+		if !errors.Is(msg.err, io.EOF) {
+			m.refreshErr = msg.err
+		}
+		return m, tea.Batch(m.refresh(), tea.Tick(m.delay(), func(time.Time) tea.Msg { return reconnectMsg{} })), true
+
+	case reconnectMsg:
+		if m.stream != nil {
+			return m, nil, true
+		}
+		return m, m.connect(), true
+
+	case pollMsg:
+		if msg.gen != m.pollGen {
+			return m, nil, true
+		}
+		return m, m.refresh(), true
+
+	case refreshMsg:
+		m.refreshing = false
+		var cmds []tea.Cmd
+		if msg.err != nil {
+			m.failures++
+			m.refreshErr = msg.err
+			if !m.watch {
+				m.err = msg.err
+			}
+		} else {
+			m.failures = 0
+			m.refreshErr = nil
+			m.refreshedAt = time.Now()
+			if msg.seq == m.seq {
+				cmds = append(cmds, m.applyRefresh(msg.configurations, msg.total))
+			}
+		}
+		switch {
+		case m.refreshAgain:
+			m.refreshAgain = false
+			cmds = append(cmds, m.refresh())
+		case m.watch && m.stream != nil:
+			// Events can be lost, e.g. when the table is not published
+			// for realtime; poll rarely as a safety net.
+			cmds = append(cmds, m.schedulePoll(watchMaxBackoff))
+		case m.watch:
+			cmds = append(cmds, m.schedulePoll(m.delay()))
+		}
+		return m, tea.Batch(cmds...), true
+	}
+	return m, nil, false
+}
+
+// watchStatus is the refresh indicator of the status line.
+func (m listModel) watchStatus() string {
+	status := ""
+	if n := len(m.changed); n > 0 {
+		status = fmt.Sprintf("%d changed | ", n)
+	}
+	switch {
+	case m.refreshErr != nil:
+		status += fmt.Sprintf("Refresh failed, retrying in %s: %v", max(time.Until(m.retryAt), 0).Round(time.Second), m.refreshErr)
+	case m.refreshedAt.IsZero():
+		status += "Watching"
+	default:
+		status += "Refreshed " + humanize.Time(m.refreshedAt)
+	}
+	if m.stream != nil {
+		status += " (live)"
+	}
+	return status
+}
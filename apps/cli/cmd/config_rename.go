@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/spf13/cobra"
+)
+
+var configRenameCmd = &cobra.Command{
+	Use:   "rename [project_name] [new_name]",
+	Short: "Rename a configuration",
+	Long: `Rename changes the project name of a configuration.
+
+The Terraform state of deployed infrastructure is keyed by project name, so a
+renamed configuration deploys as a new environment. A configuration with
+deployments in progress cannot be renamed.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, newName := args[0], args[1]
+
+		if err := validateProjectName(newName); err != nil {
+			fmt.Printf("Invalid project name %q: %v\n", newName, err)
+			os.Exit(1)
+		}
+		if newName == projectName {
+			fmt.Println("The new name is the same as the current one.")
+			os.Exit(1)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		config, err := client.GetConfigurationByProjectName(cmd.Context(), projectName)
+		if errors.Is(err, api.ErrNotFound) || (err == nil && config.ID == "") {
+			fmt.Printf("No configuration found for project: %s\n", projectName)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error fetching configuration: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ensureProjectNameFree(cmd, client, newName); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		renamed := *config
+		renamed.ProjectName = newName
+		_, err = client.UpdateConfiguration(cmd.Context(), projectName, renamed, config.UpdatedAt)
+		if errors.Is(err, api.ErrConflict) {
+			fmt.Printf("Cannot rename %s: %s\n", projectName, serverMessage(err))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error renaming configuration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Renamed configuration %s to %s.\n", projectName, newName)
+	},
+}
+
+// ensureProjectNameFree fails when a configuration named projectName exists.
+func ensureProjectNameFree(cmd *cobra.Command, client *api.Client, projectName string) error {
+	existing, err := client.GetConfigurationByProjectName(cmd.Context(), projectName)
+	if errors.Is(err, api.ErrNotFound) || (err == nil && existing.ID == "") {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error checking for %s: %w", projectName, err)
+	}
+	return fmt.Errorf("a configuration named %s already exists (%s)", projectName, existing.EnvironmentStage)
+}
+
+func init() {
+	configCmd.AddCommand(configRenameCmd)
+}
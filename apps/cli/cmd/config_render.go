@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/tree"
+	"gopkg.in/yaml.v3"
+)
+
+// configField is one line of the rendered configuration.
+type configField struct {
+	label string
+	key   string // JSON field name
+	// when names a boolean field; the line is only shown if it is enabled.
+	when string
+}
+
+type configSection struct {
+	name   string
+	fields []configField
+}
+
+// fullConfigSection holds full_config, rendered as a tree.
+const fullConfigSection = "Full Config"
+
+// configSections lays out `grape config get`. Fields not listed here are
+// shown under "Other", so new API fields are never hidden, and full_config
+// comes last.
+var configSections = []configSection{
+	{"General", []configField{
+		{label: "Project", key: "project_name"},
+		{label: "Description", key: "description"},
+		{label: "Environment", key: "environment_stage"},
+		{label: "Container Platform", key: "container_platform"},
+		{label: "Status", key: "status"},
+		{label: "ID", key: "id"},
+		{label: "Owner", key: "user_id"},
+		{label: "Created", key: "created_at"},
+		{label: "Last Updated", key: "updated_at"},
+	}},
+	{"AWS", []configField{
+		{label: "Account ID", key: "aws_account_id"},
+		{label: "Region", key: "aws_region"},
+		{label: "EKS Cluster Admins", key: "eks_cluster_admins"},
+	}},
+	{"Network", []configField{
+		{label: "Create VPC", key: "create_vpc"},
+		{label: "VPC CIDR", key: "vpc_cidr", when: "create_vpc"},
+		{label: "Enable DNS", key: "enable_dns"},
+		{label: "Hosted Zone", key: "dns_hosted_zone", when: "enable_dns"},
+		{label: "Domain Name", key: "dns_domain_name", when: "enable_dns"},
+	}},
+	{"Database", []configField{
+		{label: "Min Capacity", key: "db_min_capacity"},
+		{label: "Max Capacity", key: "db_max_capacity"},
+	}},
+	{"Security", []configField{
+		{label: "CloudFront WAF", key: "enable_cloudfront_waf"},
+		{label: "Redis", key: "enable_redis"},
+		{label: "Allowed CIDR Blocks", key: "redis_allowed_cidr_blocks", when: "enable_redis"},
+	}},
+	{"Messaging", []configField{
+		{label: "SES Queues and Topics", key: "ses_queues_topics"},
+	}},
+	{"Advanced", []configField{
+		{label: "Karpenter Auto-Scaling", key: "enable_karpenter"},
+		{label: "Terraform Version", key: "terraform_version"},
+	}},
+	{"GitOps", []configField{
+		{label: "Repository", key: "gitops_repository"},
+		{label: "Argo CD Token", key: "gitops_argocd_token"},
+		{label: "Destination", key: "enable_gitops_destination"},
+		{label: "Destinations Repository", key: "gitops_destinations_repo", when: "enable_gitops_destination"},
+		{label: "App Template", key: "gitops_app_template"},
+		{label: "App Token", key: "gitops_app_token"},
+	}},
+	{"Repositories", []configField{
+		{label: "Environment Repository", key: "environment_repository"},
+		{label: "Environment Template", key: "env_template_repo"},
+		{label: "Environment Template Branch", key: "env_template_repo_branch"},
+		{label: "Environment Git Repository", key: "env_git_repo"},
+		{label: "GitOps Template", key: "gitops_template_repo"},
+		{label: "GitOps Template Branch", key: "gitops_template_repo_branch"},
+		{label: "GitOps Destination", key: "gitops_destination_repo"},
+		{label: "Applications Template", key: "applications_template_repo"},
+		{label: "Applications Template Branch", key: "applications_template_repo_branch"},
+		{label: "Applications Destination", key: "applications_destination_repo"},
+	}},
+	{"Activity", []configField{
+		{label: "Downloads", key: "download_count"},
+		{label: "Last Downloaded", key: "last_downloaded_at"},
+	}},
+}
+
+// renderOptions selects what renderConfiguration shows.
+type renderOptions struct {
+	// sections limits the output to these sections, by sectionID. All
+	// sections are shown when empty.
+	sections []string
+	reveal   bool
+}
+
+// sectionID is the name of a section for --section: "GitOps" is "gitops"
+// and "Full Config" is "full-config".
+func sectionID(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
+// sectionIDs lists the valid --section values, in display order.
+func sectionIDs() []string {
+	ids := make([]string, 0, len(configSections)+2)
+	for _, s := range configSections {
+		ids = append(ids, sectionID(s.name))
+	}
+	return append(ids, "other", sectionID(fullConfigSection))
+}
+
+var (
+	headerStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63")).Padding(1, 0)
+	subHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("240")).Padding(0, 0, 0, 2)
+	keyStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Padding(0, 2, 0, 4)
+	valueStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
+	unsetStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+	treeStyle      = lipgloss.NewStyle().PaddingLeft(4)
+)
+
+// renderConfiguration formats a configuration for the terminal, as shown by
+// `grape config get` and the detail pane of `grape config list`. Unset
+// fields are shown as such, and credentials are masked unless opts.reveal.
+func renderConfiguration(config types.Configuration, opts renderOptions) string {
+	fields, order, err := configFields(config, opts.reveal)
+	if err != nil {
+		return fmt.Sprintf("Error rendering configuration: %v\n", err)
+	}
+
+	show := func(name string) bool {
+		return len(opts.sections) == 0 || slices.Contains(opts.sections, sectionID(name))
+	}
+	enabled := func(key string) bool {
+		n := fields[key]
+		return n != nil && n.Tag == "!!bool" && n.Value == "true"
+	}
+
+	doc := strings.Builder{}
+	doc.WriteString(headerStyle.Render("Configuration Details"))
+	doc.WriteString("\n")
+
+	listed := map[string]bool{"full_config": true}
+	section := func(name string, lines []string) {
+		if !show(name) || len(lines) == 0 {
+			return
+		}
+		doc.WriteString(subHeaderStyle.Render(name))
+		doc.WriteString("\n")
+		for _, line := range lines {
+			doc.WriteString(line)
+			doc.WriteString("\n")
+		}
+		doc.WriteString("\n")
+	}
+
+	// Labels are padded to the longest one so values line up.
+	width := 0
+	for _, s := range configSections {
+		for _, f := range s.fields {
+			width = max(width, len(f.label)+1)
+		}
+	}
+	kv := func(label, value string) string {
+		return keyStyle.Render(fmt.Sprintf("%-*s", width, label+":")) + value
+	}
+
+	for _, s := range configSections {
+		var lines []string
+		for _, f := range s.fields {
+			listed[f.key] = true
+			if f.when != "" && !enabled(f.when) {
+				continue
+			}
+			lines = append(lines, kv(f.label, formatField(fields[f.key])))
+		}
+		section(s.name, lines)
+	}
+
+	var other []string
+	for _, key := range order {
+		if !listed[key] {
+			other = append(other, kv(key, formatField(fields[key])))
+		}
+	}
+	section("Other", other)
+
+	if show(fullConfigSection) {
+		doc.WriteString(subHeaderStyle.Render(fullConfigSection))
+		doc.WriteString("\n")
+		doc.WriteString(renderFullConfig(config.FullConfig, opts.reveal))
+		doc.WriteString("\n")
+	}
+
+	return strings.TrimRight(doc.String(), "\n") + "\n"
+}
+
+// renderConfigurationTable formats a configuration for `-o table`: one
+// FIELD/VALUE row per field, in the order of renderConfiguration, with the
+// API field names and unstyled values. Unset fields are empty and
+// full_config is compact JSON.
+func renderConfigurationTable(config types.Configuration, opts renderOptions) (string, error) {
+	fields, order, err := configFields(config, opts.reveal)
+	if err != nil {
+		return "", err
+	}
+
+	show := func(name string) bool {
+		return len(opts.sections) == 0 || slices.Contains(opts.sections, sectionID(name))
+	}
+
+	var keys []string
+	listed := map[string]bool{"full_config": true}
+	for _, s := range configSections {
+		for _, f := range s.fields {
+			listed[f.key] = true
+			if show(s.name) {
+				keys = append(keys, f.key)
+			}
+		}
+	}
+	if show("Other") {
+		for _, key := range order {
+			if !listed[key] {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if show(fullConfigSection) {
+		keys = append(keys, "full_config")
+	}
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tVALUE")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", key, plainField(fields[key]))
+	}
+	w.Flush()
+	return out.String(), nil
+}
+
+// plainField renders a field value for a table: scalars as they are,
+// everything else as compact JSON, on a single line.
+func plainField(n *yaml.Node) string {
+	if n == nil || n.Tag == "!!null" {
+		return ""
+	}
+	value := n.Value
+	if n.Kind != yaml.ScalarNode {
+		var buf bytes.Buffer
+		writeJSON(&buf, n)
+		value = buf.String()
+	}
+	return strings.NewReplacer("\n", `\n`, "\t", `\t`).Replace(value)
+}
+
+// configFields decodes config by JSON field name, keeping the field order.
+// Credentials are masked unless reveal is set.
+func configFields(config types.Configuration, reveal bool) (map[string]*yaml.Node, []string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if !reveal {
+		redact.Node(&doc)
+	}
+
+	root := doc.Content[0]
+	fields := make(map[string]*yaml.Node, len(root.Content)/2)
+	order := make([]string, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i].Value
+		fields[key] = root.Content[i+1]
+		order = append(order, key)
+	}
+	return fields, order, nil
+}
+
+// formatField renders a scalar field value. Booleans read as
+// Enabled/Disabled and timestamps in a short form.
+func formatField(n *yaml.Node) string {
+	if n == nil || n.Tag == "!!null" || (n.Kind == yaml.ScalarNode && n.Value == "") {
+		return unsetStyle.Render("Not set")
+	}
+	if n.Value == redact.Mask {
+		return valueStyle.Render(redact.Mask) + " " + unsetStyle.Render("(use --reveal to show)")
+	}
+	if n.Kind != yaml.ScalarNode {
+		out, err := yaml.Marshal(n)
+		if err != nil {
+			return unsetStyle.Render("Invalid value")
+		}
+		return valueStyle.Render(strings.TrimSpace(string(out)))
+	}
+
+	switch n.Tag {
+	case "!!bool":
+		if n.Value == "true" {
+			return valueStyle.Render("Enabled")
+		}
+		return valueStyle.Render("Disabled")
+	case "!!str":
+		if t, err := time.Parse(time.RFC3339Nano, n.Value); err == nil {
+			return valueStyle.Render(t.Format("2006-01-02 15:04:05"))
+		}
+	}
+	return valueStyle.Render(n.Value)
+}
+
+// renderFullConfig renders the full_config document as a tree. It may be a
+// JSON object or, in older rows, a string holding one.
+func renderFullConfig(raw json.RawMessage, reveal bool) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		raw = json.RawMessage(text)
+	}
+	var doc yaml.Node
+	if len(raw) == 0 || yaml.Unmarshal(raw, &doc) != nil || len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+		return treeStyle.Render(unsetStyle.Render("Not set"))
+	}
+	if !reveal {
+		redact.Node(&doc)
+	}
+
+	root := tree.New().
+		EnumeratorStyle(unsetStyle.UnsetItalic().PaddingRight(1)).
+		ItemStyle(valueStyle)
+	addTreeChildren(root, doc.Content[0])
+	return treeStyle.Render(root.String())
+}
+
+func addTreeChildren(t *tree.Tree, n *yaml.Node) {
+	add := func(label string, child *yaml.Node) {
+		if child.Kind == yaml.ScalarNode || len(child.Content) == 0 {
+			t.Child(label + ": " + formatTreeValue(child))
+			return
+		}
+		sub := tree.Root(label)
+		addTreeChildren(sub, child)
+		t.Child(sub)
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			add(n.Content[i].Value, n.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			add(fmt.Sprintf("[%d]", i), item)
+		}
+	default:
+		t.Child(formatTreeValue(n))
+	}
+}
+
+func formatTreeValue(n *yaml.Node) string {
+	switch {
+	case n.Kind == yaml.MappingNode:
+		return "{}"
+	case n.Kind == yaml.SequenceNode:
+		return "[]"
+	case n.Tag == "!!null":
+		return "null"
+	case n.Value == redact.Mask:
+		return n.Value
+	case n.Tag == "!!str":
+		return fmt.Sprintf("%q", n.Value)
+	}
+	return n.Value
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfschema"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfvars"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var validateVariablesFile string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [project_name|file]",
+	Short: "Check a configuration against the template's variables",
+	Long: `Validate renders a configuration into Terraform variables and checks them
+against the variable declarations of the infrastructure template: required
+values, types, CIDR syntax and overlaps, and min <= desired <= max sizes.
+
+The argument is a project name, a configuration file (YAML or JSON, as
+written by ` + "`grape config get -o yaml`" + `) or a .tfvars file. Files are
+recognised by a path separator, their extension or a file: prefix; anything
+else is a project name.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := loadSchema()
+		if err != nil {
+			fmt.Printf("Error reading variable declarations: %v\n", err)
+			os.Exit(1)
+		}
+
+		values, fromFile, err := validationInput(args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		problems := schema.Validate(values)
+
+		if structuredOutput() {
+			result := struct {
+				Valid    bool               `json:"valid"`
+				Problems []tfschema.Problem `json:"problems"`
+			}{Valid: !tfschema.HasErrors(problems), Problems: problems}
+			if result.Problems == nil {
+				result.Problems = []tfschema.Problem{}
+			}
+			if err := printStructured(result); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			printProblems(problems, fromFile)
+			if !tfschema.HasErrors(problems) {
+				fmt.Printf("✓ %s is valid (%d variables checked)\n", args[0], len(schema.Variables))
+			}
+		}
+
+		if tfschema.HasErrors(problems) {
+			os.Exit(1)
+		}
+	},
+}
+
+func loadSchema() (*tfschema.Schema, error) {
+	if validateVariablesFile == "" {
+		return tfschema.Template()
+	}
+	src, err := os.ReadFile(validateVariablesFile)
+	if err != nil {
+		return nil, err
+	}
+	return tfschema.Parse(src, validateVariablesFile)
+}
+
+// validationInput returns the variable values for a project name or file.
+// fromFile is true when the values were read from a .tfvars file, so
+// problems can point at its lines.
+func validationInput(arg string) (values map[string]tfschema.Value, fromFile bool, err error) {
+	path, isFile := fileOperand(arg)
+	if isFile && strings.HasSuffix(path, ".tfvars") {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false, err
+		}
+		values, err := tfschema.ParseValues(src, path)
+		return values, true, err
+	}
+
+	var config *types.Configuration
+	if isFile {
+		if config, err = readConfigurationFile(path); err != nil {
+			return nil, false, err
+		}
+	} else {
+		if config, err = fetchConfiguration(arg); err != nil {
+			return nil, false, err
+		}
+		if config.ID == "" {
+			return nil, false, fmt.Errorf("no configuration found for project: %s", arg)
+		}
+	}
+
+	values, err = configurationValues(*config)
+	return values, false, err
+}
+
+// configurationValues renders a configuration into the template variables.
+func configurationValues(config types.Configuration) (map[string]tfschema.Value, error) {
+	vars, err := tfvars.FromConfiguration(config)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering configuration: %w", err)
+	}
+	return tfschema.ParseValues(vars.Bytes(), tfvars.VariablesFile)
+}
+
+// validateConfiguration checks a configuration against the bundled template.
+func validateConfiguration(config types.Configuration) ([]tfschema.Problem, error) {
+	schema, err := tfschema.Template()
+	if err != nil {
+		return nil, err
+	}
+	values, err := configurationValues(config)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Validate(values), nil
+}
+
+// fileOperand reports whether a command argument names a file rather than
+// a project, and returns its path. Files need a path separator, a
+// configuration file extension or a file: prefix, so a project is never
+// mistaken for a local file or directory of the same name.
+func fileOperand(arg string) (string, bool) {
+	if path, ok := strings.CutPrefix(arg, "file:"); ok {
+		return path, true
+	}
+	if strings.ContainsRune(arg, '/') || strings.ContainsRune(arg, filepath.Separator) {
+		return arg, true
+	}
+	switch strings.ToLower(filepath.Ext(arg)) {
+	case ".yaml", ".yml", ".json", ".tfvars":
+		return arg, true
+	}
+	return "", false
+}
+
+// readConfigurationFile reads a configuration from a YAML or JSON file that
+// uses the API field names.
+func readConfigurationFile(path string) (*types.Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON; go through JSON so the json tags apply.
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	var config types.Configuration
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", filepath.Base(path), err)
+	}
+	return &config, nil
+}
+
+func printProblems(problems []tfschema.Problem, withLines bool) {
+	var (
+		errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		warnStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+		pathStyle  = lipgloss.NewStyle().Bold(true)
+	)
+
+	for _, p := range problems {
+		mark := errorStyle.Render("✗")
+		if p.Warning {
+			mark = warnStyle.Render("!")
+		}
+		location := ""
+		if withLines && p.Range.Filename != "" {
+			location = fmt.Sprintf("%s:%d: ", p.Range.Filename, p.Range.Start.Line)
+		}
+		fmt.Printf("%s %s%s: %s\n", mark, location, pathStyle.Render(p.Path), p.Message)
+	}
+}
+
+func init() {
+	configCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&validateVariablesFile, "variables", "", "variables.tf to validate against (default: the bundled template's)")
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/deploy"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/logship"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// --- Bubble Tea Model ---
+
+type stepStatus int
+
+const (
+	stepPending stepStatus = iota
+	stepRunning
+	stepDone
+	stepFailed
+)
+
+type deployStep struct {
+	name     string
+	status   stepStatus
+	started  time.Time
+	duration time.Duration
+}
+
+type deployModel struct {
+	spinner spinner.Model
+	steps   []deployStep
+	logs    []string
+	cancel  context.CancelFunc
+	done    bool
+	err     error
+}
+
+type deployStepStartedMsg struct{ index int }
+type deployStepFinishedMsg struct {
+	index int
+	err   error
+}
+type deployLogMsg struct{ line string }
+type deployDoneMsg struct{ err error }
+
+// deployLogLines is how many trailing output lines the dashboard shows.
+const deployLogLines = 12
+
+func newDeployModel(steps []deploy.Step, cancel context.CancelFunc) deployModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+
+	m := deployModel{spinner: s, cancel: cancel}
+	for _, step := range steps {
+		m.steps = append(m.steps, deployStep{name: step.Name})
+	}
+	return m
+}
+
+func (m deployModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m deployModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			// Cancelling the context stops the running step; the runner then
+			// reports back with deployDoneMsg.
+			m.cancel()
+		}
+	case deployStepStartedMsg:
+		m.steps[msg.index].status = stepRunning
+		m.steps[msg.index].started = time.Now()
+	case deployStepFinishedMsg:
+		step := &m.steps[msg.index]
+		step.duration = time.Since(step.started)
+		step.status = stepDone
+		if msg.err != nil {
+			step.status = stepFailed
+		}
+	case deployLogMsg:
+		m.logs = append(m.logs, msg.line)
+		if len(m.logs) > deployLogLines {
+			m.logs = m.logs[len(m.logs)-deployLogLines:]
+		}
+	case deployDoneMsg:
+		m.done = true
+		m.err = msg.err
+		return m, tea.Quit
+	default:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+var (
+	deployHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63")).Padding(1, 0)
+	stepPendingStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	stepDoneStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	stepFailedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	deployLogStyle    = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("244")).
+				BorderStyle(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("240")).
+				Padding(0, 1)
+)
+
+func (m deployModel) View() string {
+	doc := strings.Builder{}
+	doc.WriteString(deployHeaderStyle.Render("Deployment"))
+	doc.WriteString("\n")
+
+	for _, step := range m.steps {
+		switch step.status {
+		case stepPending:
+			doc.WriteString(stepPendingStyle.Render("  · " + step.name))
+		case stepRunning:
+			doc.WriteString(fmt.Sprintf("%s %s (%s)", m.spinner.View(), step.name, time.Since(step.started).Round(time.Second)))
+		case stepDone:
+			doc.WriteString(stepDoneStyle.Render(fmt.Sprintf("  ✓ %s (%s)", step.name, step.duration.Round(time.Second))))
+		case stepFailed:
+			doc.WriteString(stepFailedStyle.Render(fmt.Sprintf("  ✗ %s (%s)", step.name, step.duration.Round(time.Second))))
+		}
+		doc.WriteString("\n")
+	}
+
+	if len(m.logs) > 0 {
+		doc.WriteString(deployLogStyle.Render(strings.Join(m.logs, "\n")))
+		doc.WriteString("\n")
+	}
+
+	if m.done {
+		if m.err != nil {
+			doc.WriteString(fmt.Sprintf("✗ Deployment failed: %v\n", m.err))
+		} else {
+			doc.WriteString("✓ Deployment finished.\n")
+		}
+	} else {
+		doc.WriteString(stepPendingStyle.Render("Press ctrl+c to cancel"))
+		doc.WriteString("\n")
+	}
+	return doc.String()
+}
+
+// --- Step Runner ---
+
+// runDeploySteps executes the steps in order, reporting progress to the
+// program and, when shipper is set, to the deployment logs in the portal.
+func runDeploySteps(ctx context.Context, p *tea.Program, steps []deploy.Step, shipper *logship.Shipper) {
+	for i, step := range steps {
+		p.Send(deployStepStartedMsg{index: i})
+
+		ui := logship.NewWriter(func(line string) {
+			if !revealSecrets {
+				line = redact.String(line)
+			}
+			p.Send(deployLogMsg{line: line})
+		})
+		var out io.Writer = ui
+		var shipped *logship.StepOutput
+		if shipper != nil {
+			shipped = shipper.Step(step.Name)
+			out = &teeOutput{ui: ui, shipped: shipped}
+		}
+		err := step.Run(ctx, out)
+		ui.Close()
+		if shipped != nil {
+			shipped.Close()
+			if err != nil {
+				shipper.Log(logship.LevelError, step.Name, err.Error())
+			}
+		}
+
+		p.Send(deployStepFinishedMsg{index: i, err: err})
+		if err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("cancelled during %q", step.Name)
+			}
+			p.Send(deployDoneMsg{err: err})
+			return
+		}
+	}
+	p.Send(deployDoneMsg{})
+}
+
+// teeOutput sends step output to the dashboard and the log shipper, keeping
+// stderr tagged separately for the shipper.
+type teeOutput struct {
+	ui      io.Writer
+	shipped *logship.StepOutput
+}
+
+func (t *teeOutput) Write(p []byte) (int, error) {
+	t.ui.Write(p)
+	return t.shipped.Write(p)
+}
+
+func (t *teeOutput) Stderr() io.Writer {
+	return io.MultiWriter(t.ui, t.shipped.Stderr())
+}
+
+// --- Cobra Command ---
+
+var (
+	deployOpts         deploy.Options
+	deployDeploymentID string
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy [project_name]",
+	Short: "Render and apply the Terraform templates for a configuration",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := args[0]
+
+		opts := deployOpts
+		if opts.GitToken == "" {
+			opts.GitToken = os.Getenv("GRAPE_GIT_TOKEN")
+		}
+		if opts.AWSProfile == "" && activeProfile != nil {
+			opts.AWSProfile = activeProfile.Defaults.AWSProfile
+		}
+		if opts.WorkDir == "" {
+			dir, err := os.MkdirTemp("", "grape-deploy-"+projectName+"-")
+			if err != nil {
+				fmt.Printf("Error creating working directory: %v\n", err)
+				os.Exit(1)
+			}
+			opts.WorkDir = dir
+		}
+		workDir, err := filepath.Abs(opts.WorkDir)
+		if err != nil {
+			fmt.Printf("Error resolving working directory: %v\n", err)
+			os.Exit(1)
+		}
+		opts.WorkDir = workDir
+
+		var config types.Configuration
+		steps := append([]deploy.Step{{
+			Name: "Fetch configuration",
+			Run: func(ctx context.Context, out io.Writer) error {
+				fetched, err := fetchConfiguration(projectName)
+				if err != nil {
+					return err
+				}
+				if fetched.ID == "" {
+					return fmt.Errorf("no configuration found for project: %s", projectName)
+				}
+				config = *fetched
+				fmt.Fprintf(out, "Loaded %s (%s) for account %s in %s\n", config.ProjectName, config.EnvironmentStage, config.AwsAccountID, config.AwsRegion)
+				return nil
+			},
+		}}, deploy.Steps(&config, opts)...)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := tea.NewProgram(newDeployModel(steps, cancel))
+
+		var shipper *logship.Shipper
+		if deployDeploymentID != "" {
+			shipper, err = newDeployShipper(deployDeploymentID, func(format string, args ...any) {
+				p.Send(deployLogMsg{line: fmt.Sprintf(format, args...)})
+			})
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		go runDeploySteps(ctx, p, steps, shipper)
+
+		final, err := p.Run()
+		if err != nil {
+			fmt.Printf("An error occurred: %v\n", err)
+			os.Exit(1)
+		}
+
+		if shipper != nil {
+			closeCtx, cancelClose := context.WithTimeout(context.Background(), 15*time.Second)
+			if err := shipper.Close(closeCtx); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+			cancelClose()
+		}
+
+		fmt.Printf("Working directory: %s\n", opts.WorkDir)
+		m, _ := final.(deployModel)
+		if m.err != nil {
+			os.Exit(1)
+		}
+
+		// Remember what was applied for `grape config diff <project>@deployed`.
+		if m.done && !opts.DryRun {
+			if err := saveDeployedSnapshot(config); err != nil {
+				fmt.Printf("Warning: could not record the deployed configuration: %v\n", err)
+			}
+		}
+	},
+}
+
+// newDeployShipper ships logs to an existing deployment in the portal using
+// the user's credentials.
+func newDeployShipper(deploymentID string, logf func(format string, args ...any)) (*logship.Shipper, error) {
+	tokens, err := tokenSource()
+	if err != nil {
+		return nil, err
+	}
+
+	spool, err := logship.SpoolPath(deploymentID)
+	if err != nil {
+		spool = ""
+	}
+
+	return logship.New(logship.Config{
+		URL:       fmt.Sprintf("%s/api/deployments/%s/logs", webOrigin(), deploymentID),
+		Tokens:    tokens,
+		SpoolFile: spool,
+		Logf:      logf,
+		Reveal:    revealSecrets,
+		Version:   rootCmd.Version,
+	}), nil
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.Flags().StringVar(&deployOpts.WorkDir, "dir", "", "Working directory for the template repository (default: a new temporary directory)")
+	deployCmd.Flags().StringVar(&deployOpts.AWSProfile, "aws-profile", "", "AWS profile to use for Terraform (default: the profile's aws_profile)")
+	deployCmd.Flags().StringVar(&deployOpts.GitToken, "git-token", "", "Token for cloning private template repositories (default: $GRAPE_GIT_TOKEN)")
+	deployCmd.Flags().StringVar(&deployOpts.TerraformBin, "terraform", "", "Path to the terraform executable")
+	deployCmd.Flags().StringVar(&deployDeploymentID, "deployment-id", "", "Ship logs to this deployment in the portal")
+	deployCmd.Flags().BoolVar(&deployOpts.DryRun, "dry-run", false, "Stop after terraform plan without applying changes")
+}
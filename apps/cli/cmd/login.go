@@ -1,17 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
 	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/google/uuid"
-	"github.com/imroc/req/v3"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 )
@@ -20,55 +23,126 @@ import (
 
 type model struct {
 	spinner   spinner.Model
+	client    *api.Client
+	auth      *api.DeviceAuthorization
+	expiresAt time.Time
 	loading   bool
 	done      bool
 	err       error
 	userEmail string
+
+	// After approval the user types the verification code shown in the
+	// browser into code; checking is set while it is sent.
+	verifying bool
+	checking  bool
+	code      textinput.Model
+	codeErr   error
 }
 
-func initialModel() model {
+func initialModel(client *api.Client, auth *api.DeviceAuthorization) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
+
+	code := textinput.New()
+	code.Prompt = "> "
+	code.Placeholder = "XX-00"
+	code.CharLimit = 8
+
 	return model{
-		spinner: s,
-		loading: true,
+		spinner:   s,
+		client:    client,
+		auth:      auth,
+		expiresAt: time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second),
+		loading:   true,
+		code:      code,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return m.spinner.Tick
+	return tea.Batch(m.spinner.Tick, pollForToken(m.client, m.auth))
 }
 
 type authSuccessMsg struct{ response *types.ExchangeResponse }
 type authErrorMsg struct{ err error }
 
+// verificationRequiredMsg means the login was approved in the browser.
+type verificationRequiredMsg struct{}
+
+// codeRejectedMsg means the verification code was wrong, with attempts left.
+type codeRejectedMsg struct{ err error }
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc {
 			return m, tea.Quit
 		}
+		if !m.verifying || m.checking {
+			return m, nil
+		}
+		if msg.Type == tea.KeyEnter {
+			code := strings.TrimSpace(m.code.Value())
+			if code == "" {
+				return m, nil
+			}
+			m.checking = true
+			m.codeErr = nil
+			return m, verifyCode(m.client, m.auth, code)
+		}
+		var cmd tea.Cmd
+		m.code, cmd = m.code.Update(msg)
+		return m, cmd
+	case verificationRequiredMsg:
+		m.loading = false
+		m.verifying = true
+		return m, m.code.Focus()
+	case codeRejectedMsg:
+		m.checking = false
+		m.codeErr = msg.err
+		m.code.Reset()
+		return m, nil
 	case authSuccessMsg:
 		m.loading = false
+		m.verifying = false
 		m.done = true
 		m.userEmail = msg.response.UserEmail
 		saveTokens(msg.response)
 		return m, tea.Quit
 	case authErrorMsg:
 		m.loading = false
+		m.verifying = false
 		m.err = msg.err
 		return m, tea.Quit
 	default:
-		var cmd tea.Cmd
-		m.spinner, cmd = m.spinner.Update(msg)
-		return m, cmd
+		var spinnerCmd, codeCmd tea.Cmd
+		m.spinner, spinnerCmd = m.spinner.Update(msg)
+		m.code, codeCmd = m.code.Update(msg)
+		return m, tea.Batch(spinnerCmd, codeCmd)
 	}
-	return m, nil
 }
 
 func (m model) View() string {
+	if m.verifying {
+		var b strings.Builder
+		b.WriteString("✓ Login approved in the browser.\n")
+		b.WriteString("Enter the verification code shown there:\n\n")
+		b.WriteString(m.code.View())
+		b.WriteString("\n\n")
+		switch {
+		case m.checking:
+			fmt.Fprintf(&b, "%s Checking the code...\n", m.spinner.View())
+		case m.codeErr != nil:
+			fmt.Fprintf(&b, "✗ %v\n", m.codeErr)
+		}
+		return b.String()
+	}
 	if m.loading {
-		return fmt.Sprintf("%s Waiting for authentication in the browser...", m.spinner.View())
+		status := fmt.Sprintf("%s Waiting for you to approve the login...", m.spinner.View())
+		if m.auth.ExpiresIn > 0 {
+			left := max(time.Until(m.expiresAt), 0).Round(time.Second)
+			status += fmt.Sprintf(" (code expires in %d:%02d)", int(left.Minutes()), int(left.Seconds())%60)
+		}
+		return status
 	}
 	if m.done {
 		return fmt.Sprintf("✓ Welcome, %s! You are now authenticated.\n", m.userEmail)
@@ -81,121 +155,221 @@ func (m model) View() string {
 
 // --- Polling and Token Handling ---
 
-func pollForToken(deviceCode, exchangeURL string) tea.Cmd {
+// slowDownIncrement is added to the poll interval on every slow_down.
+const slowDownIncrement = 5 * time.Second
+
+// pollForToken polls the token endpoint every auth.Interval seconds until the
+// login is approved, denied or expires (RFC 8628 §3.5). Approval is reported
+// as verificationRequiredMsg, since tokens need the verification code.
+func pollForToken(client *api.Client, auth *api.DeviceAuthorization) tea.Cmd {
 	return func() tea.Msg {
-		client := req.C().SetTimeout(120 * time.Second) // Overall timeout for the polling
-		for {
-			var result types.ExchangeResponse
-			var errMsg struct {
-				Error string `json:"error"`
-			}
-			resp, err := client.R().
-				SetBody(map[string]string{"device_code": deviceCode}).
-				SetSuccessResult(&result).
-				SetErrorResult(&errMsg).
-				Post(exchangeURL)
-
-			if err != nil {
-				return authErrorMsg{err: fmt.Errorf("failed to connect to server: %w", err)}
-			}
+		interval := time.Duration(auth.Interval) * time.Second
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+		var deadline time.Time
+		if auth.ExpiresIn > 0 {
+			deadline = time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+		}
 
-			if resp.IsSuccessState() {
-				return authSuccessMsg{response: &result}
+		for {
+			time.Sleep(interval)
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return authErrorMsg{err: fmt.Errorf("%w. Run `grape login` again", api.ErrExpiredToken)}
 			}
 
-			if resp.StatusCode != 404 { // 404 is our "pending" state, any other error is fatal
-				return authErrorMsg{err: fmt.Errorf("authentication failed (HTTP %d): %s", resp.StatusCode, errMsg.Error)}
+			result, err := client.Exchange(context.Background(), auth.DeviceCode, "")
+			switch {
+			case err == nil:
+				return authSuccessMsg{response: result}
+			case errors.Is(err, api.ErrVerificationRequired):
+				return verificationRequiredMsg{}
+			case errors.Is(err, api.ErrAuthorizationPending):
+				// Not approved yet, wait and try again
+			case errors.Is(err, api.ErrSlowDown):
+				interval += slowDownIncrement
+			case errors.Is(err, api.ErrExpiredToken):
+				return authErrorMsg{err: fmt.Errorf("%w. Run `grape login` again", err)}
+			case errors.Is(err, api.ErrAccessDenied):
+				return authErrorMsg{err: err}
+			default:
+				return authErrorMsg{err: fmt.Errorf("authentication failed: %w", err)}
 			}
-
-			// If it's a 404, just wait and try again
-			time.Sleep(2 * time.Second)
 		}
 	}
 }
 
-
+// verifyCode sends the verification code typed by the user. A wrong code can
+// be retried until the server cancels the login.
+func verifyCode(client *api.Client, auth *api.DeviceAuthorization, code string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := client.Exchange(context.Background(), auth.DeviceCode, code)
+		switch {
+		case err == nil:
+			return authSuccessMsg{response: result}
+		case errors.Is(err, api.ErrInvalidVerificationCode):
+			return codeRejectedMsg{err: err}
+		case errors.Is(err, api.ErrTooManyAttempts), errors.Is(err, api.ErrExpiredToken):
+			return authErrorMsg{err: fmt.Errorf("%w. Run `grape login` again", err)}
+		default:
+			return authErrorMsg{err: fmt.Errorf("authentication failed: %w", err)}
+		}
+	}
+}
 
 func saveTokens(tokens *types.ExchangeResponse) {
-	credsPath, err := getCredentialsPath()
+	store, err := credentialStore()
 	if err != nil {
-		fmt.Printf("Error getting credentials path: %v\n", err)
+		fmt.Printf("Error opening credential store: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(credsPath), 0755); err != nil {
-		fmt.Printf("Error creating config directory: %v\n", err)
-		os.Exit(1)
-	}
-
-	file, err := os.Create(credsPath)
+	// Wait for a refresh in another grape process, so it cannot overwrite
+	// the new tokens with the old ones.
+	unlock, err := store.Lock(context.Background())
 	if err != nil {
-		fmt.Printf("Error creating credentials file: %v\n", err)
+		fmt.Printf("Error locking the %s: %v\n", store.Name(), err)
 		os.Exit(1)
 	}
-	defer file.Close()
+	defer unlock()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(tokens); err != nil {
-		fmt.Printf("Error writing tokens to file: %v\n", err)
+	if err := store.Save(*tokens); err != nil {
+		fmt.Printf("Error saving credentials to the %s: %v\n", store.Name(), err)
 		os.Exit(1)
 	}
 }
 
 // --- Cobra Command ---
 
-var forceLogin bool
+var (
+	forceLogin bool
+	noBrowser  bool
+	loginToken string
+)
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with the platform",
+	Long: `Login signs in with the OAuth 2.0 device flow. It prints a URL and a
+one-time code; open the URL in a browser on any device, sign in to the
+portal and approve the code. The CLI waits until the login is approved,
+denied or the code expires.
+
+After approval the browser shows a short verification code, such as KX-47,
+which you type into the CLI to finish. The login is cancelled after 5
+incorrect codes.
+
+Where no browser can be used, such as in CI, pass an API token created with
+` + "`grape token create`" + ` to --token instead ("-" reads it from stdin), or
+set GRAPE_TOKEN or --token-file on each command without logging in.`,
+	Example: `  grape login
+  grape login --no-browser
+  echo "$GRAPE_CI_TOKEN" | grape login --token -`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if loginToken != "" {
+			loginWithToken(cmd.Context(), loginToken)
+			return
+		}
+
 		// 1. Check if already authenticated (unless forced)
+		if _, source, _ := providedToken(); source != "" && !forceLogin {
+			fmt.Printf("You are authenticated with the API token from %s.\n", source)
+			fmt.Println("Use --force to log in with the browser anyway.")
+			return
+		}
 		if !forceLogin {
-			if _, err := getAuthToken(); err == nil {
-				// We need to fetch the email for display purposes since getAuthToken returns only the token
-				credsPath, _ := getCredentialsPath()
-				file, _ := os.ReadFile(credsPath)
-				var creds types.ExchangeResponse
-				json.Unmarshal(file, &creds)
-				
-				fmt.Printf("You are already logged in as: %s\n", creds.UserEmail)
+			if status := localAuthStatus(cmd.Context()); status.Authenticated {
+				fmt.Printf("You are already logged in as: %s\n", status.Email)
 				fmt.Println("Use --force to log in again.")
 				return
 			}
 		}
 
 		// 2. Proceed with login flow
-		deviceCode := uuid.New().String()
-		webOrigin := os.Getenv("GRAPE_WEB_ORIGIN")
-		if webOrigin == "" {
-			webOrigin = "https://localhost:3000"
+		client := anonymousClient()
+		auth, err := client.Authorize(cmd.Context())
+		if err != nil {
+			fmt.Printf("Error starting login: %v\n", err)
+			os.Exit(1)
 		}
-		loginURL := fmt.Sprintf("%s/cli/login?device_code=%s", webOrigin, deviceCode)
-		exchangeURL := fmt.Sprintf("%s/api/auth/cli/exchange", webOrigin)
-
-		fmt.Println("Please open the following URL in your browser to log in:")
-		fmt.Println(loginURL)
+		printUserCode(auth)
 
-		if err := browser.OpenURL(loginURL); err != nil {
-			fmt.Printf("\nCould not open browser automatically. Please open the link manually.\n")
+		if !noBrowser {
+			openURL := auth.VerificationURIComplete
+			if openURL == "" {
+				openURL = auth.VerificationURI
+			}
+			if err := browser.OpenURL(openURL); err != nil {
+				fmt.Printf("Could not open browser automatically. Please open the link manually.\n\n")
+			}
 		}
 
-		p := tea.NewProgram(initialModel())
-		go func() {
-			// This is a bit of a hack to ensure the Bubble Tea UI has time to render before polling starts
-			time.Sleep(100 * time.Millisecond)
-			p.Send(pollForToken(deviceCode, exchangeURL)())
-		}()
-
-		if _, err := p.Run(); err != nil {
+		p := tea.NewProgram(initialModel(client, auth))
+		final, err := p.Run()
+		if err != nil {
 			fmt.Printf("An error occurred: %v\n", err)
 			os.Exit(1)
 		}
+		if m, ok := final.(model); ok && m.err != nil {
+			os.Exit(1)
+		}
 	},
 }
 
+// loginWithToken checks an API token against the portal and stores it in
+// place of the login tokens of the active profile.
+func loginWithToken(ctx context.Context, token string) {
+	if token == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Printf("Error reading token from stdin: %v\n", err)
+			os.Exit(1)
+		}
+		token = string(data)
+	}
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		fmt.Printf("This is not a Grape API token; they start with %q. Create one with `grape token create`.\n", apiTokenPrefix)
+		os.Exit(1)
+	}
+
+	client := api.New(api.Config{
+		Origin:  webOrigin(),
+		Version: rootCmd.Version,
+		Tokens:  &fixedToken{token: token, source: "--token"},
+	})
+	if _, err := client.ListConfigurations(ctx, api.ListOptions{Limit: 1}); err != nil {
+		fmt.Printf("Error checking the token: %v\n", err)
+		os.Exit(1)
+	}
+
+	saveTokens(&types.ExchangeResponse{AccessToken: token})
+	fmt.Println("✓ Logged in with the API token.")
+}
+
+// printUserCode tells the user where to approve the login. The code is
+// what matters, since the URL can be opened on any device.
+func printUserCode(auth *api.DeviceAuthorization) {
+	codeStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("63")).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(0, 2).
+		MarginLeft(2)
+
+	fmt.Println("To log in, open this URL in a browser on any device:")
+	fmt.Println()
+	fmt.Printf("  %s\n", auth.VerificationURI)
+	fmt.Println()
+	fmt.Println("and enter the code:")
+	fmt.Println(codeStyle.Render(auth.UserCode))
+	fmt.Println()
+}
+
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().BoolVarP(&forceLogin, "force", "f", false, "Force re-authentication")
+	loginCmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Don't open a browser, e.g. when logging in over SSH")
+	loginCmd.Flags().StringVar(&loginToken, "token", "", `Log in with an API token instead of the browser; "-" reads it from stdin`)
 }
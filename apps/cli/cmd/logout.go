@@ -1,37 +1,125 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/credentials"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/profiles"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
 
 	"github.com/spf13/cobra"
 )
 
+// revokeTimeout bounds the revocation, so logging out never hangs on an
+// unreachable portal.
+const revokeTimeout = 15 * time.Second
+
+var logoutAll bool
+
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Log out from the platform",
+	Long: `Logout revokes the login session on the portal, then removes the stored
+credentials of the active profile (see --profile). If the session cannot
+be revoked, for example because the portal is unreachable, the credentials
+are removed anyway and a warning is printed.
+
+With --all, every CLI session of the account is revoked, on all machines
+and profiles. API tokens stay valid; revoke them with ` + "`grape token revoke`" + `.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		credsPath, err := getCredentialsPath()
+		profile := activeProfileName
+		if profile == "" {
+			profile = profiles.DefaultName
+		}
+
+		store, err := credentialStore()
 		if err != nil {
-			fmt.Printf("Error getting credentials path: %v\n", err)
+			fmt.Printf("Error opening credential store: %v\n", err)
 			os.Exit(1)
 		}
 
-		if _, err := os.Stat(credsPath); os.IsNotExist(err) {
+		creds, err := store.Load()
+		if errors.Is(err, credentials.ErrNotFound) {
+			if logoutAll {
+				fmt.Printf("You are not logged in to profile %q. Log in to revoke all sessions.\n", profile)
+				os.Exit(1)
+			}
 			fmt.Println("You are not currently logged in.")
 			return
 		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: error reading credentials from the %s: %v\n", store.Name(), err)
+		} else {
+			ctx, cancel := context.WithTimeout(cmd.Context(), revokeTimeout)
+			revokeSessions(ctx, store, creds)
+			cancel()
+		}
 
-		if err := os.Remove(credsPath); err != nil {
+		if err := deleteCredentials(cmd.Context(), store); err != nil && !errors.Is(err, credentials.ErrNotFound) {
 			fmt.Printf("Error logging out: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Println("Successfully logged out.")
+		fmt.Printf("Successfully logged out of profile %q.\n", profile)
 	},
 }
 
+// revokeSessions revokes the session of creds, or with --all every session
+// of the account. Failures only warn: the local credentials go either way.
+func revokeSessions(ctx context.Context, store credentials.Store, creds *types.ExchangeResponse) {
+	if strings.HasPrefix(creds.AccessToken, apiTokenPrefix) {
+		fmt.Println("The API token stays valid; revoke it with `grape token revoke` if it is no longer needed.")
+		if logoutAll {
+			fmt.Fprintln(os.Stderr, "Warning: --all needs a browser login; no sessions were revoked.")
+		}
+		return
+	}
+
+	if logoutAll {
+		client := api.New(api.Config{
+			Origin:  webOrigin(),
+			Version: rootCmd.Version,
+			Tokens:  &storeTokens{store: store, client: anonymousClient()},
+		})
+		n, err := client.RevokeAllSessions(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not revoke your sessions on the portal: %v\n", err)
+			return
+		}
+		sessions := "sessions"
+		if n == 1 {
+			sessions = "session"
+		}
+		fmt.Printf("Revoked %d %s on %s.\n", n, sessions, webOrigin())
+		return
+	}
+
+	if creds.RefreshToken == "" {
+		return
+	}
+	if err := anonymousClient().RevokeSession(ctx, creds.RefreshToken); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not revoke the session on the portal: %v\n", err)
+	}
+}
+
+// deleteCredentials removes the stored credentials once no other grape
+// process is refreshing them, which would save them again.
+func deleteCredentials(ctx context.Context, store credentials.Store) error {
+	unlock, err := store.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return store.Delete()
+}
+
 func init() {
 	rootCmd.AddCommand(logoutCmd)
+	logoutCmd.Flags().BoolVar(&logoutAll, "all", false, "Revoke every CLI session of your account, on all machines")
 }
-
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/configdiff"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// Values of the global --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// revealSecrets is the global --reveal flag. Without it, credentials are
+// masked in everything the CLI prints, exports or ships; see package redact.
+var revealSecrets bool
+
+// outputFormat is the global --output flag. Empty means the interactive view
+// on a terminal and a plain table otherwise.
+var outputFormat string
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case "", outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q (expected %s, %s or %s)", outputFormat, outputJSON, outputYAML, outputTable)
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// interactive reports whether commands may start a TUI or prompt: no output
+// format was requested and both stdin and stdout are terminals.
+func interactive() bool {
+	return outputFormat == "" && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// structuredOutput reports whether -o json or -o yaml was given.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printStructured writes v to stdout as JSON or YAML, using the JSON field
+// names and order for both. Credentials are masked unless --reveal is given.
+func printStructured(v any) error {
+	node, err := jsonNode(v)
+	if err != nil {
+		return err
+	}
+	if !revealSecrets {
+		redact.Node(node)
+	}
+
+	if outputFormat == outputJSON {
+		var compact, out bytes.Buffer
+		writeJSON(&compact, node)
+		if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+			return err
+		}
+		_, err = fmt.Println(out.String())
+		return err
+	}
+
+	plainStyle(node)
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+// jsonNode encodes v as JSON and decodes it into a yaml.Node, which keeps
+// the field order.
+func jsonNode(v any) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// writeJSON writes a node decoded by jsonNode back as compact JSON.
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		writeJSON(buf, n.Content[0])
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(n.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			writeJSON(buf, n.Content[i+1])
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, c)
+		}
+		buf.WriteByte(']')
+	default:
+		if n.Tag == "!!str" {
+			value, _ := json.Marshal(n.Value)
+			buf.Write(value)
+		} else {
+			// Numbers, booleans and null keep their JSON spelling.
+			buf.WriteString(n.Value)
+		}
+	}
+}
+
+// plainStyle drops the JSON quoting and flow style from a decoded node tree
+// so it is rendered as block YAML.
+func plainStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		plainStyle(c)
+	}
+}
+
+// redactChanges masks credentials in diff values unless --reveal is given.
+// Values of sensitive fields, at any depth of the path, are masked entirely.
+func redactChanges(changes []configdiff.Change) []configdiff.Change {
+	if revealSecrets {
+		return changes
+	}
+	out := make([]configdiff.Change, len(changes))
+	for i, c := range changes {
+		if redact.Key(c.Path) {
+			c.Old = redact.Value(c.Path, c.Old)
+			c.New = redact.Value(c.Path, c.New)
+		} else {
+			c.Old, c.New = redact.String(c.Old), redact.String(c.New)
+		}
+		out[i] = c
+	}
+	return out
+}
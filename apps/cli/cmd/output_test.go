@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/configdiff"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+const testAPIToken = "grape_0123456789abcdefghijkl"
+
+func TestRedactChanges(t *testing.T) {
+	changes := []configdiff.Change{
+		{Path: "gitops_app_token", Kind: configdiff.Changed, Old: "old-secret", New: "new-secret"},
+		{Path: "full_config.gitops.argocd_token", Kind: configdiff.Added, New: "secret"},
+		{Path: "description", Kind: configdiff.Changed, Old: "a shop", New: "uses " + testAPIToken},
+	}
+
+	tests := []struct {
+		name   string
+		reveal bool
+		want   []configdiff.Change
+	}{
+		{
+			name: "masked",
+			want: []configdiff.Change{
+				{Path: "gitops_app_token", Kind: configdiff.Changed, Old: redact.Mask, New: redact.Mask},
+				{Path: "full_config.gitops.argocd_token", Kind: configdiff.Added, New: redact.Mask},
+				{Path: "description", Kind: configdiff.Changed, Old: "a shop", New: "uses " + redact.Mask},
+			},
+		},
+		{
+			name:   "revealed",
+			reveal: true,
+			want:   changes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(reveal bool) { revealSecrets = reveal }(revealSecrets)
+			revealSecrets = tt.reveal
+
+			if got := redactChanges(changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactChanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if changes[0].Old != "old-secret" {
+		t.Error("redactChanges() modified its argument")
+	}
+}
+
+func TestRenderConfigurationReveal(t *testing.T) {
+	token := "argocd-secret"
+	config := types.Configuration{
+		ID:                "c1",
+		ProjectName:       "shop",
+		GitopsArgocdToken: &token,
+		FullConfig:        json.RawMessage(`{"gitops":{"app_token":"app-secret"},"notes":"` + testAPIToken + `"}`),
+	}
+
+	tests := []struct {
+		name   string
+		reveal bool
+		shown  []string
+		hidden []string
+	}{
+		{
+			name:   "masked",
+			shown:  []string{redact.Mask},
+			hidden: []string{"argocd-secret", "app-secret", testAPIToken},
+		},
+		{
+			name:   "revealed",
+			reveal: true,
+			shown:  []string{"argocd-secret", "app-secret", testAPIToken},
+			hidden: []string{redact.Mask},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := renderOptions{reveal: tt.reveal}
+			table, err := renderConfigurationTable(config, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, out := range map[string]string{"renderConfiguration": renderConfiguration(config, opts), "renderConfigurationTable": table} {
+				for _, s := range tt.shown {
+					if !strings.Contains(out, s) {
+						t.Errorf("%s() does not show %q:\n%s", name, s, out)
+					}
+				}
+				for _, s := range tt.hidden {
+					if strings.Contains(out, s) {
+						t.Errorf("%s() shows %q:\n%s", name, s, out)
+					}
+				}
+			}
+		})
+	}
+}
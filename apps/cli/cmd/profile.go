@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/credentials"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/profiles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// profileFlag is the global --profile flag.
+	profileFlag string
+
+	// activeProfileName and activeProfile are resolved before every command.
+	activeProfileName string
+	activeProfile     *profiles.Profile
+)
+
+// loadProfiles reads the profiles from the grape config directory.
+func loadProfiles() (*profiles.Config, error) {
+	dir, err := credentials.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("error getting config directory: %w", err)
+	}
+	return profiles.Load(dir)
+}
+
+// resolveProfile selects the profile from --profile, GRAPE_PROFILE or the
+// current profile in config.yaml, in that order.
+func resolveProfile() error {
+	cfg, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	name := profileFlag
+	if name == "" {
+		name = os.Getenv("GRAPE_PROFILE")
+	}
+	if name == "" {
+		name = cfg.CurrentName()
+	}
+
+	p, ok := cfg.Get(name)
+	if !ok {
+		return fmt.Errorf("profile %q does not exist. Run `grape profile list` to see available profiles", name)
+	}
+	activeProfileName, activeProfile = name, p
+	return nil
+}
+
+// webOrigin returns the portal URL of the active profile.
+func webOrigin() string {
+	if activeProfile == nil {
+		return (&profiles.Profile{}).ResolveOrigin()
+	}
+	return activeProfile.ResolveOrigin()
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named profiles for different portals and accounts",
+	// Profile management must keep working when the selected profile is
+	// missing, otherwise there would be no way to fix it.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		resolveProfile()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Use `grape profile list`, `use`, `add` or `remove`")
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadProfiles()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tNAME\tORIGIN\tUSER")
+		for _, name := range cfg.Names() {
+			p, _ := cfg.Get(name)
+
+			marker := ""
+			if name == activeProfileName {
+				marker = "*"
+			}
+
+			user := "-"
+			if store, err := openProfileStore(name); err == nil {
+				if creds, err := store.Load(); err == nil {
+					user = creds.UserEmail
+				}
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, p.ResolveOrigin(), user)
+		}
+		w.Flush()
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Set the profile used when --profile is not given",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		cfg, err := loadProfiles()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if _, ok := cfg.Get(name); !ok {
+			fmt.Printf("Profile %q does not exist.\n", name)
+			os.Exit(1)
+		}
+
+		cfg.Current = name
+		if err := cfg.Save(); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Switched to profile %q.\n", name)
+	},
+}
+
+var (
+	profileAddOrigin     string
+	profileAddAWSProfile string
+	profileAddUse        bool
+)
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Add a profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := profiles.ValidateName(name); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := profiles.ValidateOrigin(profileAddOrigin); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		cfg, err := loadProfiles()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if _, ok := cfg.Profiles[name]; ok {
+			fmt.Printf("Profile %q already exists.\n", name)
+			os.Exit(1)
+		}
+
+		cfg.Profiles[name] = &profiles.Profile{
+			Origin:   profileAddOrigin,
+			Defaults: profiles.Defaults{AWSProfile: profileAddAWSProfile},
+		}
+		if profileAddUse {
+			cfg.Current = name
+		}
+		if err := cfg.Save(); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Added profile %q for %s.\n", name, profileAddOrigin)
+		fmt.Printf("Run `grape login --profile %s` to authenticate.\n", name)
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a profile and its stored credentials",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if name == profiles.DefaultName {
+			fmt.Println("The default profile cannot be removed.")
+			os.Exit(1)
+		}
+
+		cfg, err := loadProfiles()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if _, ok := cfg.Profiles[name]; !ok {
+			fmt.Printf("Profile %q does not exist.\n", name)
+			os.Exit(1)
+		}
+
+		if store, err := openProfileStore(name); err == nil {
+			if err := deleteCredentials(cmd.Context(), store); err != nil && !errors.Is(err, credentials.ErrNotFound) {
+				fmt.Printf("Warning: could not remove credentials: %v\n", err)
+			}
+		}
+
+		delete(cfg.Profiles, name)
+		if cfg.Current == name {
+			cfg.Current = ""
+		}
+		if err := cfg.Save(); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed profile %q.\n", name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileUseCmd, profileAddCmd, profileRemoveCmd)
+
+	profileAddCmd.Flags().StringVar(&profileAddOrigin, "origin", "", "URL of the portal, e.g. https://beta.idp.itgix.com")
+	profileAddCmd.Flags().StringVar(&profileAddAWSProfile, "aws-profile", "", "Default AWS profile for deployments")
+	profileAddCmd.Flags().BoolVar(&profileAddUse, "use", false, "Switch to the new profile")
+	profileAddCmd.MarkFlagRequired("origin")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -13,11 +14,50 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Welcome to grape CLI!")
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(); err != nil {
+			return err
+		}
+		if err := resolveProfile(); err != nil {
+			// The flags were fine; don't bury the error under the usage text.
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Output format: json, yaml or table (default: interactive view on a terminal, table otherwise)")
+	rootCmd.PersistentFlags().BoolVar(&revealSecrets, "reveal", false, "Show tokens and other credentials instead of masking them")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile to use (defaults to GRAPE_PROFILE or the current profile)")
+	rootCmd.PersistentFlags().StringVar(&tokenFile, "token-file", "", "Read the API token from this file instead of the stored credentials (see also GRAPE_TOKEN)")
 }
 
-func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+// exitCodeAnnotation sets the exit status of a command for usage errors and
+// errors of PersistentPreRunE, such as an unknown flag or profile. Commands
+// whose exit status carries a result, like config diff, use it to keep that
+// status unambiguous.
+const exitCodeAnnotation = "grape/error-exit-code"
+
+// Execute runs the CLI. version is reported by --version and in the
+// User-Agent of API requests.
+func Execute(version string) {
+	rootCmd.Version = version
+
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(errorExitCode(cmd))
+	}
+}
+
+func errorExitCode(cmd *cobra.Command) int {
+	if cmd != nil {
+		if code, err := strconv.Atoi(cmd.Annotations[exitCodeAnnotation]); err == nil {
+			return code
+		}
 	}
+	return 1
 }
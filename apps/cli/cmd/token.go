@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/spf13/cobra"
+)
+
+// maxTokenLifetime matches the limit enforced by the portal.
+const maxTokenLifetime = 365 * 24 * time.Hour
+
+// tokenScopes are the scopes an API token can be given. configs:write
+// includes configs:read.
+var tokenScopes = []string{"configs:read", "configs:write"}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage API tokens for CI and other non-interactive use",
+	Long: `API tokens authenticate the CLI without a browser. Create one while logged
+in, then pass it to CI through the GRAPE_TOKEN environment variable, the
+--token-file flag or ` + "`grape login --token -`" + `.
+
+Tokens are limited to their scopes and expire after at most a year. They
+cannot be used to manage other tokens.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Use `grape token create`, `list` or `revoke`")
+	},
+}
+
+var (
+	tokenCreateScopes  []string
+	tokenCreateExpires string
+)
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an API token",
+	Long: `Create makes a new API token and prints it. The token is shown only once;
+store it in your CI secrets right away.`,
+	Example: `  grape token create ci-read --scope configs:read --expires 30d
+  grape token create deployer --scope configs:write --expires 2026-12-31`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		for _, scope := range tokenCreateScopes {
+			if !slices.Contains(tokenScopes, scope) {
+				fmt.Printf("Unknown scope %q (expected %s)\n", scope, strings.Join(tokenScopes, " or "))
+				os.Exit(1)
+			}
+		}
+		expiresAt, err := parseExpiry(tokenCreateExpires, time.Now())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if time.Until(expiresAt) > maxTokenLifetime {
+			fmt.Println("Tokens can be valid for at most a year; use a shorter --expires.")
+			os.Exit(1)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		token, secret, err := client.CreateToken(cmd.Context(), name, tokenCreateScopes, expiresAt)
+		if errors.Is(err, api.ErrConflict) || errors.Is(err, api.ErrForbidden) {
+			fmt.Printf("Cannot create token %s: %s\n", name, serverMessage(err))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error creating token: %v\n", err)
+			os.Exit(1)
+		}
+
+		if structuredOutput() {
+			// The token is shown only once, so it is never masked.
+			revealSecrets = true
+			err := printStructured(struct {
+				types.APIToken
+				Token string `json:"token"`
+			}{*token, secret})
+			if err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Printf("✓ Created token %s with %s, valid until %s.\n\n",
+			token.Name, strings.Join(token.Scopes, ", "), formatExpiry(token.ExpiresAt))
+		fmt.Println("Copy it now, it will not be shown again:")
+		fmt.Println()
+		fmt.Printf("  %s\n", secret)
+		fmt.Println()
+		fmt.Println("Use it with GRAPE_TOKEN, --token-file or `grape login --token -`.")
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API tokens",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		tokens, err := client.ListTokens(cmd.Context())
+		if errors.Is(err, api.ErrForbidden) {
+			fmt.Printf("Cannot list tokens: %s\n", serverMessage(err))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error listing tokens: %v\n", err)
+			os.Exit(1)
+		}
+
+		if structuredOutput() {
+			if err := printStructured(tokens); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if len(tokens) == 0 {
+			fmt.Println("No API tokens. Create one with `grape token create`.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTOKEN\tSCOPES\tEXPIRES\tLAST USED\tID")
+		for _, t := range tokens {
+			lastUsed := "never"
+			if t.LastUsedAt != nil {
+				lastUsed = formatTime(*t.LastUsedAt)
+			}
+			fmt.Fprintf(w, "%s\t%s…\t%s\t%s\t%s\t%s\n",
+				t.Name,
+				t.Prefix,
+				strings.Join(t.Scopes, ","),
+				formatExpiry(t.ExpiresAt),
+				lastUsed,
+				t.ID,
+			)
+		}
+		w.Flush()
+	},
+}
+
+var tokenRevokeYes bool
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke [name|id]",
+	Short: "Revoke an API token",
+	Long: `Revoke makes an API token unusable right away. Anything still using it,
+such as a CI pipeline, fails to authenticate from then on.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := apiClient()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		tokens, err := client.ListTokens(cmd.Context())
+		if errors.Is(err, api.ErrForbidden) {
+			fmt.Printf("Cannot revoke tokens: %s\n", serverMessage(err))
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Error listing tokens: %v\n", err)
+			os.Exit(1)
+		}
+		i := slices.IndexFunc(tokens, func(t types.APIToken) bool {
+			return t.ID == args[0] || t.Name == args[0]
+		})
+		if i < 0 {
+			fmt.Printf("No API token named %s.\n", args[0])
+			os.Exit(1)
+		}
+		token := tokens[i]
+
+		if !tokenRevokeYes {
+			if !interactive() {
+				fmt.Println("Refusing to revoke without confirmation. Pass --yes to skip the prompt.")
+				os.Exit(1)
+			}
+			confirmed := false
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("Revoke token %s (%s…)? Anything using it stops working.", token.Name, token.Prefix),
+			}
+			survey.AskOne(prompt, &confirmed)
+			if !confirmed {
+				fmt.Println("Aborted.")
+				return
+			}
+		}
+
+		if err := client.RevokeToken(cmd.Context(), token.ID); err != nil {
+			fmt.Printf("Error revoking token: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Revoked token %s.\n", token.Name)
+	},
+}
+
+// parseExpiry accepts a lifetime such as 30d or 12h, counted from now, or a
+// date (2006-01-02) or RFC 3339 timestamp.
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	var t time.Time
+	if m := daysPattern.FindStringSubmatch(s); m != nil {
+		days, _ := strconv.Atoi(m[1])
+		t = now.AddDate(0, 0, days)
+	} else if d, err := time.ParseDuration(s); err == nil {
+		t = now.Add(d)
+	} else {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if parsed, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				t = parsed
+				break
+			}
+		}
+	}
+
+	if t.IsZero() {
+		return t, fmt.Errorf("invalid --expires %q (expected a lifetime like 30d or 12h, or a date like 2026-12-31)", s)
+	}
+	if !t.After(now) {
+		return t, fmt.Errorf("--expires %s is in the past", s)
+	}
+	return t, nil
+}
+
+func formatExpiry(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenRevokeCmd)
+	tokenCreateCmd.Flags().StringSliceVar(&tokenCreateScopes, "scope", []string{"configs:read"}, "scope to grant, repeatable: "+strings.Join(tokenScopes, ", "))
+	tokenCreateCmd.Flags().StringVar(&tokenCreateExpires, "expires", "30d", "lifetime (30d, 12h) or expiry date (2026-12-31), at most a year")
+	tokenRevokeCmd.Flags().BoolVarP(&tokenRevokeYes, "yes", "y", false, "revoke without asking for confirmation")
+}
@@ -0,0 +1,182 @@
+package configdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of change.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// MetadataFields are maintained by the portal and differ between any two
+// configurations, so they are skipped unless requested.
+var MetadataFields = []string{"id", "user_id", "created_at", "updated_at", "download_count", "last_downloaded_at"}
+
+// Change is a single differing field.
+type Change struct {
+	// Path is the field's JSON path, e.g. full_config.eks.node_groups[0].min_size.
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	// Old and New are the rendered values; empty for added and removed fields.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// Options controls Compare.
+type Options struct {
+	// Ignore lists top-level fields to skip.
+	Ignore []string
+}
+
+// Compare returns the fields that differ between a and b, in field order.
+// The full_config JSON is compared field by field.
+func Compare(a, b types.Configuration, opts Options) ([]Change, error) {
+	left, err := flatten(a, opts.Ignore)
+	if err != nil {
+		return nil, err
+	}
+	right, err := flatten(b, opts.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	leftIndex := make(map[string]int, len(left))
+	for i, l := range left {
+		leftIndex[l.path] = i
+	}
+
+	// Fields only in b are shown after the closest field before them that
+	// both sides have, so nested additions stay next to their siblings.
+	rightByPath := make(map[string]string, len(right))
+	added := map[int][]leaf{}
+	anchor := -1
+	for _, r := range right {
+		rightByPath[r.path] = r.value
+		if i, ok := leftIndex[r.path]; ok {
+			anchor = i
+		} else {
+			added[anchor] = append(added[anchor], r)
+		}
+	}
+
+	var changes []Change
+	addAfter := func(i int) {
+		for _, r := range added[i] {
+			changes = append(changes, Change{Path: r.path, Kind: Added, New: r.value})
+		}
+	}
+	addAfter(-1)
+	for i, l := range left {
+		r, ok := rightByPath[l.path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: l.path, Kind: Removed, Old: l.value})
+		case r != l.value:
+			changes = append(changes, Change{Path: l.path, Kind: Changed, Old: l.value, New: r})
+		}
+		addAfter(i)
+	}
+	return changes, nil
+}
+
+type leaf struct {
+	path  string
+	value string
+}
+
+// flatten turns a configuration into its leaf values in field order.
+func flatten(config types.Configuration, ignore []string) ([]leaf, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	// yaml.Node keeps the key order of the JSON document.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, name := range ignore {
+		skip[name] = true
+	}
+
+	var leaves []leaf
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		if skip[key] {
+			continue
+		}
+		if key == "full_config" {
+			if nested, ok := parseEmbedded(value); ok {
+				value = nested
+			}
+		}
+		walk(key, value, &leaves)
+	}
+	return leaves, nil
+}
+
+// parseEmbedded parses a string holding a JSON object or array.
+func parseEmbedded(n *yaml.Node) (*yaml.Node, bool) {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		return nil, false
+	}
+	text := strings.TrimSpace(n.Value)
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return nil, false
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, false
+	}
+	return doc.Content[0], true
+}
+
+func walk(path string, n *yaml.Node, leaves *[]leaf) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			*leaves = append(*leaves, leaf{path, "{}"})
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			walk(path+"."+n.Content[i].Value, n.Content[i+1], leaves)
+		}
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			*leaves = append(*leaves, leaf{path, "[]"})
+		}
+		for i, item := range n.Content {
+			walk(fmt.Sprintf("%s[%d]", path, i), item, leaves)
+		}
+	case yaml.AliasNode:
+		walk(path, n.Alias, leaves)
+	default:
+		*leaves = append(*leaves, leaf{path, renderScalar(n)})
+	}
+}
+
+// renderScalar shows strings quoted only when they would otherwise be
+// mistaken for another type or be invisible.
+func renderScalar(n *yaml.Node) string {
+	if n.Tag == "!!null" {
+		return "null"
+	}
+	if n.Tag == "!!str" {
+		// "", "1", "true" and "null" would read as other JSON values.
+		var v any
+		if n.Value == "" || json.Unmarshal([]byte(n.Value), &v) == nil {
+			return fmt.Sprintf("%q", n.Value)
+		}
+	}
+	return n.Value
+}
@@ -0,0 +1,156 @@
+package configdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+func strPtr(s string) *string { return &s }
+
+func base() types.Configuration {
+	return types.Configuration{
+		ID:               "c1",
+		ProjectName:      "shop",
+		EnvironmentStage: "development",
+		AwsRegion:        "eu-west-1",
+		VpcCidr:          strPtr("10.0.0.0/16"),
+		FullConfig:       json.RawMessage(`{"eks":{"node_groups":[{"min_size":1,"max_size":3}]},"tags":{}}`),
+		UpdatedAt:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	changes, err := Compare(base(), base(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Compare() of equal configurations = %v, want no changes", changes)
+	}
+}
+
+func TestCompareIgnoresMetadata(t *testing.T) {
+	b := base()
+	b.ID = "c2"
+	b.UpdatedAt = b.UpdatedAt.Add(time.Hour)
+
+	changes, err := Compare(base(), b, Options{Ignore: MetadataFields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Compare() = %v, want metadata ignored", changes)
+	}
+
+	changes, err = Compare(base(), b, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	if want := []string{"id", "updated_at"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Compare() without Ignore changed %v, want %v", paths, want)
+	}
+}
+
+func TestCompareFlattensFullConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		fullConfig string
+		want       []Change
+	}{
+		{
+			name:       "nested value changed",
+			fullConfig: `{"eks":{"node_groups":[{"min_size":2,"max_size":3}]},"tags":{}}`,
+			want: []Change{
+				{Path: "full_config.eks.node_groups[0].min_size", Kind: Changed, Old: "1", New: "2"},
+			},
+		},
+		{
+			name:       "field added next to its siblings",
+			fullConfig: `{"eks":{"node_groups":[{"min_size":1,"max_size":3,"desired_size":2}]},"tags":{}}`,
+			want: []Change{
+				{Path: "full_config.eks.node_groups[0].desired_size", Kind: Added, New: "2"},
+			},
+		},
+		{
+			name:       "list item removed",
+			fullConfig: `{"eks":{"node_groups":[]},"tags":{}}`,
+			want: []Change{
+				{Path: "full_config.eks.node_groups", Kind: Added, New: "[]"},
+				{Path: "full_config.eks.node_groups[0].min_size", Kind: Removed, Old: "1"},
+				{Path: "full_config.eks.node_groups[0].max_size", Kind: Removed, Old: "3"},
+			},
+		},
+		{
+			name:       "string encoded document",
+			fullConfig: `"{\"eks\":{\"node_groups\":[{\"min_size\":1,\"max_size\":3}]},\"tags\":{}}"`,
+		},
+		{
+			name:       "string that looks like another type",
+			fullConfig: `{"eks":{"node_groups":[{"min_size":"1","max_size":3}]},"tags":{}}`,
+			want: []Change{
+				{Path: "full_config.eks.node_groups[0].min_size", Kind: Changed, Old: "1", New: `"1"`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := base()
+			b.FullConfig = json.RawMessage(tt.fullConfig)
+
+			changes, err := Compare(base(), b, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(changes, tt.want) {
+				t.Errorf("Compare() = %+v, want %+v", changes, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareTopLevelFields(t *testing.T) {
+	b := base()
+	b.AwsRegion = "eu-central-1"
+	b.VpcCidr = nil
+	b.Description = strPtr("")
+
+	changes, err := Compare(base(), b, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Path: "aws_region", Kind: Changed, Old: "eu-west-1", New: "eu-central-1"},
+		{Path: "description", Kind: Changed, Old: "null", New: `""`},
+		{Path: "vpc_cidr", Kind: Changed, Old: "10.0.0.0/16", New: "null"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Compare() = %+v, want %+v", changes, want)
+	}
+}
+
+// The --json output of `grape config diff` is the changes as encoded here.
+func TestChangeJSON(t *testing.T) {
+	changes := []Change{
+		{Path: "aws_region", Kind: Changed, Old: "eu-west-1", New: "eu-central-1"},
+		{Path: "full_config.tags.team", Kind: Added, New: "shop"},
+		{Path: "vpc_cidr", Kind: Removed, Old: "10.0.0.0/16"},
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"path":"aws_region","kind":"changed","old":"eu-west-1","new":"eu-central-1"},` +
+		`{"path":"full_config.tags.team","kind":"added","new":"shop"},` +
+		`{"path":"vpc_cidr","kind":"removed","old":"10.0.0.0/16"}]`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
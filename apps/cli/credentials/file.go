@@ -0,0 +1,193 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// EncryptedFile stores credentials AES-256-GCM encrypted on disk. The key is
+// generated on first use and kept in a separate file; both are 0600. This
+// keeps tokens out of backups and casual reads of the config directory, but
+// unlike the keyring it does not protect against other processes of the
+// same user.
+type EncryptedFile struct {
+	fileLock
+	path    string
+	keyPath string
+}
+
+// NewEncryptedFile returns the store at path, encrypted with the key at
+// keyPath and locked with the file at lockPath.
+func NewEncryptedFile(path, keyPath, lockPath string) *EncryptedFile {
+	return &EncryptedFile{fileLock: fileLock{lockPath: lockPath}, path: path, keyPath: keyPath}
+}
+
+func (f *EncryptedFile) Name() string { return "encrypted file " + f.path }
+
+func (f *EncryptedFile) Load() (*types.ExchangeResponse, error) {
+	sealed, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(f.keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials key: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("credentials file is corrupted")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	data, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("credentials file could not be decrypted")
+	}
+
+	var creds types.ExchangeResponse
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (f *EncryptedFile) Save(creds types.ExchangeResponse) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+
+	key, err := f.loadOrCreateKey()
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	return writeFile0600(f.path, gcm.Seal(nonce, nonce, data, nil))
+}
+
+func (f *EncryptedFile) Delete() error {
+	err := os.Remove(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (f *EncryptedFile) loadOrCreateKey() ([]byte, error) {
+	key, err := os.ReadFile(f.keyPath)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	created, err := createFile0600(f.keyPath, key)
+	if err != nil {
+		return nil, fmt.Errorf("error writing credentials key: %w", err)
+	}
+	if !created {
+		// Another grape process created the key first.
+		return os.ReadFile(f.keyPath)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFile0600 replaces the file at path with data, readable only by the
+// owner. The data is written to a temporary file that is renamed over path,
+// so readers see either the old or the new contents, never a partial write.
+func writeFile0600(path string, data []byte) error {
+	tmp, err := writeTemp(path, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// createFile0600 is writeFile0600 for a file that must not be replaced. It
+// reports false, leaving the file alone, when it already exists.
+func createFile0600(path string, data []byte) (bool, error) {
+	tmp, err := writeTemp(path, data)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp)
+
+	err = os.Link(tmp, path)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeTemp writes data to a new 0600 file next to path and returns its
+// name.
+func writeTemp(path string, data []byte) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	name := file.Name()
+
+	err = file.Chmod(0600)
+	if err == nil {
+		_, err = file.Write(data)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
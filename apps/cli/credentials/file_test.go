@@ -0,0 +1,200 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+func newTestFile(t *testing.T) (*EncryptedFile, string) {
+	t.Helper()
+	dir := t.TempDir()
+	return NewEncryptedFile(filepath.Join(dir, encryptedFile), filepath.Join(dir, keyFile), filepath.Join(dir, lockFile)), dir
+}
+
+var testCreds = types.ExchangeResponse{
+	AccessToken:  "access-token",
+	RefreshToken: "refresh-token",
+	UserEmail:    "dev@example.com",
+}
+
+func TestEncryptedFileRoundTrip(t *testing.T) {
+	store, dir := newTestFile(t)
+
+	if _, err := store.Load(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load() of an empty store error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Save(testCreds); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != testCreds {
+		t.Errorf("Load() = %+v, want %+v", *got, testCreds)
+	}
+
+	for _, name := range []string{encryptedFile, keyFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s mode = %v, want 0600", name, perm)
+		}
+	}
+	sealed, err := os.ReadFile(filepath.Join(dir, encryptedFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte(testCreds.AccessToken)) {
+		t.Error("credentials file contains the access token in plaintext")
+	}
+
+	// Saving again keeps the key and replaces the credentials.
+	key, _ := os.ReadFile(filepath.Join(dir, keyFile))
+	updated := testCreds
+	updated.AccessToken = "new-access-token"
+	if err := store.Save(updated); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Load(); err != nil || got.AccessToken != "new-access-token" {
+		t.Errorf("Load() after a second Save() = %+v, %v", got, err)
+	}
+	if newKey, _ := os.ReadFile(filepath.Join(dir, keyFile)); !bytes.Equal(key, newKey) {
+		t.Error("Save() replaced the existing key")
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, ".*.tmp")); len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestEncryptedFileWrongKey(t *testing.T) {
+	store, dir := newTestFile(t)
+	if err := store.Save(testCreds); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, keyFile), bytes.Repeat([]byte{1}, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Load() with another key succeeded")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, encryptedFile), []byte("short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); err == nil {
+		t.Error("Load() of a truncated file succeeded")
+	}
+}
+
+func TestOpenSeparatesProfiles(t *testing.T) {
+	t.Setenv("GRAPE_CREDENTIALS_STORE", BackendFile)
+	dir := t.TempDir()
+
+	def, err := Open(dir, DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staging, err := Open(dir, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := def.Save(testCreds); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := staging.Load(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() of another profile error = %v, want %v", err, ErrNotFound)
+	}
+	if err := staging.Save(types.ExchangeResponse{AccessToken: "staging"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := def.Load(); err != nil || got.AccessToken != testCreds.AccessToken {
+		t.Errorf("Load() of the default profile = %+v, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "credentials-staging.enc")); err != nil {
+		t.Errorf("profile credentials file: %v", err)
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	tests := []struct {
+		name     string
+		legacy   string
+		migrated bool
+		want     *types.ExchangeResponse
+		wantErr  bool
+	}{
+		{
+			name:     "credentials moved",
+			legacy:   `{"access_token":"access-token","refresh_token":"refresh-token","user_email":"dev@example.com"}`,
+			migrated: true,
+			want:     &testCreds,
+		},
+		{
+			name:   "empty credentials removed",
+			legacy: `{"access_token":""}`,
+		},
+		{
+			name:    "malformed file kept",
+			legacy:  `{"access_token":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, dir := newTestFile(t)
+			legacy := filepath.Join(dir, legacyFile)
+			if err := os.WriteFile(legacy, []byte(tt.legacy), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			migrated, err := MigrateLegacy(dir, store)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateLegacy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if migrated != tt.migrated {
+				t.Errorf("MigrateLegacy() = %v, want %v", migrated, tt.migrated)
+			}
+
+			_, statErr := os.Stat(legacy)
+			if tt.wantErr != (statErr == nil) {
+				t.Errorf("legacy file exists = %v, want %v", statErr == nil, tt.wantErr)
+			}
+
+			got, err := store.Load()
+			if tt.want == nil {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("Load() = %+v, %v, want nothing stored", got, err)
+				}
+				return
+			}
+			if err != nil || *got != *tt.want {
+				t.Errorf("Load() = %+v, %v, want %+v", got, err, *tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyWithoutFile(t *testing.T) {
+	store, dir := newTestFile(t)
+	migrated, err := MigrateLegacy(dir, store)
+	if err != nil || migrated {
+		t.Errorf("MigrateLegacy() = %v, %v, want false, nil", migrated, err)
+	}
+}
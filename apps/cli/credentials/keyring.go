@@ -0,0 +1,63 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/zalando/go-keyring"
+)
+
+// Keyring stores credentials in the OS keychain: the Secret Service on Linux,
+// Keychain on macOS and the Credential Manager on Windows.
+type Keyring struct {
+	fileLock
+	service string
+	account string
+}
+
+// NewKeyring returns the keyring entry of account, locked with the file at
+// lockPath.
+func NewKeyring(service, account, lockPath string) *Keyring {
+	return &Keyring{fileLock: fileLock{lockPath: lockPath}, service: service, account: account}
+}
+
+func (k *Keyring) Name() string { return "system keyring" }
+
+// Available reports whether the keyring can be reached.
+func (k *Keyring) Available() bool {
+	_, err := keyring.Get(k.service, k.account)
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (k *Keyring) Load() (*types.ExchangeResponse, error) {
+	secret, err := keyring.Get(k.service, k.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var creds types.ExchangeResponse
+	if err := json.Unmarshal([]byte(secret), &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (k *Keyring) Save(creds types.ExchangeResponse) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(k.service, k.account, string(data))
+}
+
+func (k *Keyring) Delete() error {
+	err := keyring.Delete(k.service, k.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
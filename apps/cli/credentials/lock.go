@@ -0,0 +1,59 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockTimeout bounds the wait for another grape process, which holds the
+// lock only while it refreshes or saves the credentials. It is a variable
+// for tests.
+var lockTimeout = 30 * time.Second
+
+const lockPollInterval = 50 * time.Millisecond
+
+// fileLock is a lock shared by all grape processes, held on a file next to
+// the credentials. It is released when the process exits, even if it
+// crashes.
+type fileLock struct {
+	lockPath string
+}
+
+// Lock waits until no other grape process holds the lock, then takes it.
+// Locks are held per open file, so Lock must not be called again before
+// unlock, even from the same process.
+func (l fileLock) Lock(ctx context.Context) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(l.lockPath), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(l.lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error locking %s: %w", l.lockPath, err)
+		}
+		if locked {
+			return func() {
+				unlockFile(file)
+				file.Close()
+			}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, fmt.Errorf("timed out waiting for another grape process to release %s", l.lockPath)
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
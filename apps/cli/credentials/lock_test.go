@@ -0,0 +1,90 @@
+package credentials
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockBlocksUntilReleased(t *testing.T) {
+	lock := fileLock{lockPath: filepath.Join(t.TempDir(), "grape", lockFile)}
+	ctx := context.Background()
+
+	unlock, err := lock.Lock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		// A second open file conflicts like another process would.
+		unlock, err := lock.Lock(ctx)
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		acquired <- unlock
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Lock() returned while the lock was held")
+	case <-time.After(5 * lockPollInterval):
+	}
+
+	unlock()
+	select {
+	case unlock2 := <-acquired:
+		if unlock2 != nil {
+			unlock2()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Lock() did not return after the lock was released")
+	}
+
+	// Released locks can be taken again.
+	unlock, err = lock.Lock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
+
+func TestLockTimeout(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 3 * lockPollInterval
+
+	lock := fileLock{lockPath: filepath.Join(t.TempDir(), lockFile)}
+	unlock, err := lock.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	start := time.Now()
+	_, err = lock.Lock(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out waiting for another grape process") {
+		t.Fatalf("Lock() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed < lockTimeout {
+		t.Errorf("Lock() gave up after %v, want at least %v", elapsed, lockTimeout)
+	}
+}
+
+func TestLockCancelled(t *testing.T) {
+	lock := fileLock{lockPath: filepath.Join(t.TempDir(), lockFile)}
+	unlock, err := lock.Lock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*lockPollInterval)
+	defer cancel()
+	if _, err := lock.Lock(ctx); err == nil {
+		t.Error("Lock() succeeded with a cancelled context while the lock was held")
+	}
+}
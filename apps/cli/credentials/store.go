@@ -0,0 +1,116 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// ErrNotFound is returned by Load when no credentials are stored.
+var ErrNotFound = errors.New("credentials not found")
+
+// Store persists the tokens issued by `grape login`.
+type Store interface {
+	// Name describes the backend for messages, e.g. "system keyring".
+	Name() string
+	Load() (*types.ExchangeResponse, error)
+	Save(creds types.ExchangeResponse) error
+	Delete() error
+	// Lock keeps other grape processes from changing the credentials until
+	// unlock is called, so that only one of them refreshes the tokens.
+	Lock(ctx context.Context) (unlock func(), err error)
+}
+
+// Backends selectable through GRAPE_CREDENTIALS_STORE.
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+)
+
+// DefaultProfile is the profile whose credentials use the unsuffixed names.
+const DefaultProfile = "default"
+
+const (
+	keyringService = "grape"
+	keyringAccount = "credentials"
+	encryptedFile  = "credentials.enc"
+	keyFile        = "credentials.key"
+	lockFile       = "credentials.lock"
+	legacyFile     = "credentials.json"
+)
+
+// Dir returns the directory grape keeps its local state in.
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "grape"), nil
+}
+
+// Open returns the credential store for a profile. The system keyring is
+// preferred; when it is unavailable (e.g. no Secret Service on a headless
+// Linux box) an encrypted file is used instead. GRAPE_CREDENTIALS_STORE
+// forces a backend.
+func Open(dir, profile string) (Store, error) {
+	// The default profile keeps the names used before profiles existed.
+	account, fileName, lockName := keyringAccount, encryptedFile, lockFile
+	if profile != "" && profile != DefaultProfile {
+		account = keyringAccount + ":" + profile
+		fileName = "credentials-" + profile + ".enc"
+		lockName = "credentials-" + profile + ".lock"
+	}
+
+	lockPath := filepath.Join(dir, lockName)
+	keyring := NewKeyring(keyringService, account, lockPath)
+	file := NewEncryptedFile(filepath.Join(dir, fileName), filepath.Join(dir, keyFile), lockPath)
+
+	switch backend := os.Getenv("GRAPE_CREDENTIALS_STORE"); backend {
+	case BackendKeyring:
+		return keyring, nil
+	case BackendFile:
+		return file, nil
+	case "":
+		if keyring.Available() {
+			return keyring, nil
+		}
+		return file, nil
+	default:
+		return nil, fmt.Errorf("unknown GRAPE_CREDENTIALS_STORE %q (expected %q or %q)", backend, BackendKeyring, BackendFile)
+	}
+}
+
+// MigrateLegacy moves a plaintext credentials.json written by older versions
+// into store (which should be the default profile's) and removes it. It
+// reports whether anything was migrated.
+func MigrateLegacy(dir string, store Store) (bool, error) {
+	path := filepath.Join(dir, legacyFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	var creds types.ExchangeResponse
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return false, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	if creds.AccessToken != "" {
+		if err := store.Save(creds); err != nil {
+			return false, fmt.Errorf("error moving credentials to the %s: %w", store.Name(), err)
+		}
+	}
+
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("credentials were copied to the %s but %s could not be removed: %w", store.Name(), path, err)
+	}
+	return creds.AccessToken != "", nil
+}
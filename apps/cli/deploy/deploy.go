@@ -0,0 +1,283 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/tfvars"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Paths of the rendered variable files, relative to the working directory.
+// They mirror the layout of packages/templates.
+const (
+	VarFile     = "variable-template/terraform.tfvars"
+	BackendFile = "backends/backend.tfvars"
+	PlanFile    = "tfplan"
+)
+
+// terraformWaitDelay is how long an interrupted terraform may take to stop,
+// write its state and release the state lock before it is killed.
+const terraformWaitDelay = 2 * time.Minute
+
+// Options controls how a configuration is deployed.
+type Options struct {
+	// WorkDir is where the env template repository is cloned and Terraform runs.
+	WorkDir string
+	// GitToken authenticates the clone of private template repositories.
+	GitToken string
+	// AWSProfile is exported as AWS_PROFILE to Terraform when set.
+	AWSProfile string
+	// TerraformBin overrides the terraform executable found on PATH.
+	TerraformBin string
+	// DryRun stops after terraform plan.
+	DryRun bool
+}
+
+// Step is a single unit of a deployment. Output is written to out line by line.
+type Step struct {
+	Name string
+	Run  func(ctx context.Context, out io.Writer) error
+}
+
+// StderrWriter is implemented by step outputs that record error output
+// separately. Other writers receive both streams.
+type StderrWriter interface {
+	Stderr() io.Writer
+}
+
+// Steps returns the deployment pipeline for a configuration. The
+// configuration is read when each step runs, so it may be filled in by an
+// earlier step.
+func Steps(config *types.Configuration, opts Options) []Step {
+	steps := []Step{
+		{Name: "Check Terraform version", Run: func(ctx context.Context, out io.Writer) error {
+			return CheckTerraformVersion(ctx, *config, opts, out)
+		}},
+		{Name: "Clone template repository", Run: func(ctx context.Context, out io.Writer) error {
+			return CloneTemplate(ctx, *config, opts, out)
+		}},
+		{Name: "Render variables", Run: func(ctx context.Context, out io.Writer) error {
+			return RenderVariables(*config, opts.WorkDir, out)
+		}},
+		{Name: "Terraform init", Run: func(ctx context.Context, out io.Writer) error {
+			return Terraform(ctx, opts, out, "init", "-input=false", "-no-color", "-reconfigure", "-backend-config="+BackendFile)
+		}},
+		{Name: "Terraform plan", Run: func(ctx context.Context, out io.Writer) error {
+			return Terraform(ctx, opts, out, "plan", "-input=false", "-no-color", "-var-file="+VarFile, "-out="+PlanFile)
+		}},
+	}
+	if !opts.DryRun {
+		steps = append(steps, Step{Name: "Terraform apply", Run: func(ctx context.Context, out io.Writer) error {
+			return Terraform(ctx, opts, out, "apply", "-input=false", "-no-color", "-auto-approve", PlanFile)
+		}})
+	}
+	return steps
+}
+
+// CloneTemplate clones the configuration's env template repository into the
+// working directory, or updates it to the latest commit if it was cloned
+// before.
+func CloneTemplate(ctx context.Context, config types.Configuration, opts Options, out io.Writer) error {
+	if config.EnvTemplateRepo == "" {
+		return errors.New("configuration has no env_template_repo")
+	}
+
+	var auth *http.BasicAuth
+	if opts.GitToken != "" {
+		auth = &http.BasicAuth{Username: "x-access-token", Password: opts.GitToken}
+	}
+
+	var ref plumbing.ReferenceName
+	if config.EnvTemplateRepoBranch != "" {
+		ref = plumbing.NewBranchReferenceName(config.EnvTemplateRepoBranch)
+	}
+
+	repo, err := git.PlainOpen(opts.WorkDir)
+	if err == nil {
+		fmt.Fprintf(out, "Updating %s in %s\n", config.EnvTemplateRepo, opts.WorkDir)
+		if err := updateTemplate(ctx, repo, ref, auth, out); err != nil {
+			return fmt.Errorf("error updating template repository: %w", err)
+		}
+		return nil
+	}
+	if !errors.Is(err, git.ErrRepositoryNotExists) {
+		return fmt.Errorf("error opening %s: %w", opts.WorkDir, err)
+	}
+
+	fmt.Fprintf(out, "Cloning %s into %s\n", config.EnvTemplateRepo, opts.WorkDir)
+	cloneOpts := &git.CloneOptions{
+		URL:           config.EnvTemplateRepo,
+		ReferenceName: ref,
+		SingleBranch:  true,
+		Depth:         1,
+		Progress:      out,
+	}
+	if auth != nil {
+		cloneOpts.Auth = auth
+	}
+	if _, err := git.PlainCloneContext(ctx, opts.WorkDir, false, cloneOpts); err != nil {
+		return fmt.Errorf("error cloning template repository: %w", err)
+	}
+	return nil
+}
+
+// updateTemplate fetches ref, or the checked out branch when ref is empty,
+// and resets the working tree to it. The clone is shallow and the branch may
+// have been force-pushed, so the local branch is moved to the fetched commit
+// instead of merging into it. Untracked files, like .terraform, are kept.
+func updateTemplate(ctx context.Context, repo *git.Repository, ref plumbing.ReferenceName, auth *http.BasicAuth, out io.Writer) error {
+	if ref == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return err
+		}
+		if !head.Name().IsBranch() {
+			return errors.New("the checkout is not on a branch")
+		}
+		ref = head.Name()
+	}
+
+	remoteRef := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, ref.Short())
+	fetchOpts := &git.FetchOptions{
+		RefSpecs: []gitconfig.RefSpec{gitconfig.RefSpec("+" + ref.String() + ":" + remoteRef.String())},
+		Depth:    1,
+		Force:    true,
+		Progress: out,
+	}
+	if auth != nil {
+		fetchOpts.Auth = auth
+	}
+	if err := repo.FetchContext(ctx, fetchOpts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	remote, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return fmt.Errorf("error resolving %s: %w", remoteRef.Short(), err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(ref, remote.Hash())); err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Checkout(&git.CheckoutOptions{Branch: ref, Force: true})
+}
+
+// RenderVariables writes terraform.tfvars and backend.tfvars for the
+// configuration into the working directory. They may hold credentials, so
+// only the owner can read them.
+func RenderVariables(config types.Configuration, workDir string, out io.Writer) error {
+	vars, err := tfvars.FromConfiguration(config)
+	if err != nil {
+		return fmt.Errorf("error rendering variables: %w", err)
+	}
+
+	files := map[string][]byte{
+		VarFile:     vars.Bytes(),
+		BackendFile: tfvars.Backend(config).Bytes(),
+	}
+	for _, name := range []string{VarFile, BackendFile} {
+		path := filepath.Join(workDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[name], 0600); err != nil {
+			return fmt.Errorf("error writing %s: %w", name, err)
+		}
+		// WriteFile keeps the mode of a file left by an earlier run.
+		if err := os.Chmod(path, 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Wrote %s\n", path)
+	}
+	return nil
+}
+
+// CheckTerraformVersion fails when the configuration pins a Terraform
+// version other than the one of the terraform executable, since a state
+// written by a newer Terraform cannot be read by an older one.
+func CheckTerraformVersion(ctx context.Context, config types.Configuration, opts Options, out io.Writer) error {
+	installed, err := TerraformVersion(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	want := strings.TrimPrefix(config.TerraformVersion, "v")
+	if want != "" && want != installed {
+		return fmt.Errorf("the configuration requires Terraform %s, but %s is %s; install %s or pass --terraform", want, terraformBin(opts), installed, want)
+	}
+	fmt.Fprintf(out, "Using Terraform %s\n", installed)
+	return nil
+}
+
+// TerraformVersion returns the version of the terraform executable.
+func TerraformVersion(ctx context.Context, opts Options) (string, error) {
+	bin := terraformBin(opts)
+	output, err := exec.CommandContext(ctx, bin, "version", "-json").Output()
+	if err != nil {
+		return "", fmt.Errorf("error running %s version: %w", bin, err)
+	}
+
+	var version struct {
+		TerraformVersion string `json:"terraform_version"`
+	}
+	if err := json.Unmarshal(output, &version); err != nil || version.TerraformVersion == "" {
+		return "", fmt.Errorf("could not read the version of %s", bin)
+	}
+	return version.TerraformVersion, nil
+}
+
+func terraformBin(opts Options) string {
+	if opts.TerraformBin != "" {
+		return opts.TerraformBin
+	}
+	return "terraform"
+}
+
+// Terraform runs a terraform subcommand in the working directory. When ctx is
+// cancelled terraform is interrupted, so it can stop cleanly and release the
+// state lock, and only killed if it has not exited after terraformWaitDelay.
+func Terraform(ctx context.Context, opts Options, out io.Writer, args ...string) error {
+	bin := terraformBin(opts)
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Cancel = func() error {
+		// Interrupts cannot be sent on Windows.
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = terraformWaitDelay
+	cmd.Dir = opts.WorkDir
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if sw, ok := out.(StderrWriter); ok {
+		cmd.Stderr = sw.Stderr()
+	}
+	cmd.Env = append(os.Environ(), "TF_IN_AUTOMATION=1", "TF_INPUT=0")
+	if opts.AWSProfile != "" {
+		cmd.Env = append(cmd.Env, "AWS_PROFILE="+opts.AWSProfile)
+	}
+
+	fmt.Fprintf(out, "$ %s %s\n", filepath.Base(bin), args[0])
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("terraform %s failed: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package deploy
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFiles replaces the tracked files of the repository in dir with files
+// and commits them on top of HEAD, or of parent when it is set, as after a
+// force-push of rewritten history.
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string, parent plumbing.Hash) plumbing.Hash {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range idx.Entries {
+		if _, ok := files[entry.Name]; !ok {
+			if _, err := wt.Remove(entry.Name); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}}
+	if !parent.IsZero() {
+		opts.Parents = []plumbing.Hash{parent}
+	}
+	hash, err := wt.Commit("template", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parent.IsZero() {
+		head, err := repo.Head()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), hash)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hash
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCloneTemplateFollowsForcePush(t *testing.T) {
+	upstreamDir := t.TempDir()
+	upstream, err := git.PlainInit(upstreamDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := commitFiles(t, upstream, upstreamDir, map[string]string{"main.tf": "# v1\n", "old.tf": "# old\n"}, plumbing.ZeroHash)
+
+	for _, branch := range []string{"", "master"} {
+		t.Run("branch "+branch, func(t *testing.T) {
+			config := types.Configuration{EnvTemplateRepo: upstreamDir, EnvTemplateRepoBranch: branch}
+			opts := Options{WorkDir: filepath.Join(t.TempDir(), "work")}
+			ctx := context.Background()
+
+			if err := CloneTemplate(ctx, config, opts, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+			// Terraform's files are untracked and must survive updates.
+			if err := os.MkdirAll(filepath.Join(opts.WorkDir, ".terraform"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			commitFiles(t, upstream, upstreamDir, map[string]string{"main.tf": "# v2\n", "old.tf": "# old\n"}, plumbing.ZeroHash)
+			if err := CloneTemplate(ctx, config, opts, io.Discard); err != nil {
+				t.Fatalf("CloneTemplate() after a new commit: %v", err)
+			}
+			if got := readFile(t, filepath.Join(opts.WorkDir, "main.tf")); got != "# v2\n" {
+				t.Errorf("main.tf = %q after a new commit, want v2", got)
+			}
+
+			want := commitFiles(t, upstream, upstreamDir, map[string]string{"main.tf": "# rewritten\n"}, first)
+			if err := CloneTemplate(ctx, config, opts, io.Discard); err != nil {
+				t.Fatalf("CloneTemplate() after a force-push: %v", err)
+			}
+			if got := readFile(t, filepath.Join(opts.WorkDir, "main.tf")); got != "# rewritten\n" {
+				t.Errorf("main.tf = %q after a force-push, want the rewritten file", got)
+			}
+			if _, err := os.Stat(filepath.Join(opts.WorkDir, "old.tf")); !os.IsNotExist(err) {
+				t.Errorf("old.tf still exists after a force-push removed it: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(opts.WorkDir, ".terraform")); err != nil {
+				t.Errorf(".terraform removed by the update: %v", err)
+			}
+
+			repo, err := git.PlainOpen(opts.WorkDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			head, err := repo.Head()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if head.Hash() != want || head.Name() != plumbing.NewBranchReferenceName("master") {
+				t.Errorf("HEAD = %s at %s, want master at %s", head.Name(), head.Hash(), want)
+			}
+		})
+	}
+}
+
+func TestRenderVariablesMode(t *testing.T) {
+	dir := t.TempDir()
+	// A file from an earlier version, written world-readable.
+	stale := filepath.Join(dir, VarFile)
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := types.Configuration{
+		ProjectName:      "test",
+		EnvironmentStage: "dev",
+		AwsRegion:        "eu-central-1",
+		AwsAccountID:     "123456789012",
+	}
+	if err := RenderVariables(config, dir, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{VarFile, BackendFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s mode = %v, want 0600", name, perm)
+		}
+	}
+}
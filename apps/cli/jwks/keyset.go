@@ -0,0 +1,161 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// cacheTTL is how long a cached key set is used before it is fetched again.
+// A token signed with an unknown key triggers a fetch sooner.
+const cacheTTL = 24 * time.Hour
+
+// cachedKeySet is the cache file of a portal.
+type cachedKeySet struct {
+	Origin    string       `json:"origin"`
+	FetchedAt time.Time    `json:"fetched_at"`
+	KeySet    types.KeySet `json:"key_set"`
+}
+
+// keySet returns the key set of the portal, from the cache unless it is
+// stale or refetch is set, and whether it was just fetched. When the portal
+// cannot be reached a stale cache is used rather than failing.
+func (v *Verifier) keySet(ctx context.Context, refetch bool) (*types.KeySet, bool, error) {
+	cached, _ := v.readCache()
+	if cached != nil && !refetch && time.Since(cached.FetchedAt) < cacheTTL {
+		return &cached.KeySet, false, nil
+	}
+
+	set, err := v.Fetch(ctx)
+	if err != nil {
+		if cached != nil {
+			return &cached.KeySet, false, nil
+		}
+		return nil, false, fmt.Errorf("could not fetch the signing keys of %s: %w", v.Origin, err)
+	}
+	if err := v.writeCache(cachedKeySet{Origin: v.Origin, FetchedAt: time.Now(), KeySet: *set}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not cache the signing keys: %v\n", err)
+	}
+	return set, true, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+func (v *Verifier) cachePath() string {
+	if v.CacheDir == "" {
+		return ""
+	}
+	name := unsafeChars.ReplaceAllString(v.Origin, "_")
+	return filepath.Join(v.CacheDir, name+".json")
+}
+
+func (v *Verifier) readCache() (*cachedKeySet, error) {
+	path := v.cachePath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cached cachedKeySet
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+	if cached.Origin != v.Origin {
+		return nil, nil
+	}
+	return &cached, nil
+}
+
+func (v *Verifier) writeCache(cached cachedKeySet) error {
+	path := v.cachePath()
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func findKey(set *types.KeySet, kid string) (types.JSONWebKey, bool) {
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		// A single key may be published without a kid.
+		if key.Kid == kid || (kid == "" && len(set.Keys) == 1) {
+			return key, true
+		}
+	}
+	return types.JSONWebKey{}, false
+}
+
+// parseKey returns the public key of a JWK.
+func parseKey(key types.JSONWebKey) (any, error) {
+	switch key.Kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", key.Crv)
+		}
+		x, err := decodeInt(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("key %q is not on curve %s", key.Kid, key.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "RSA":
+		n, err := decodeInt(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(key.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("key %q has an invalid exponent", key.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", key.Kty)
+	}
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid key parameter %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,183 @@
+// Package jwks verifies the tokens issued by `grape login` against the
+// public keys the portal publishes, caching the keys between runs.
+package jwks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrExpired is returned for a token that is genuine but past its expiry.
+	ErrExpired = errors.New("token expired")
+	// ErrInvalid is returned for a token that is malformed, has a bad
+	// signature or lacks required claims.
+	ErrInvalid = errors.New("invalid token")
+	// ErrWrongServer is returned for a token issued by another portal: its
+	// issuer or audience differ.
+	ErrWrongServer = errors.New("token issued by a different portal")
+	// ErrUnknownKey is returned for a token signed with a key the portal
+	// does not publish, even after fetching its keys again. Usually the key
+	// has been retired.
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+	// ErrUnverifiable is returned for an HS256 token while the portal
+	// publishes no keys, unless Verifier.AllowClaimsOnly is set.
+	ErrUnverifiable = errors.New("token signature cannot be checked locally")
+)
+
+// DefaultLeeway is the clock skew tolerated between the CLI and the portal.
+const DefaultLeeway = time.Minute
+
+// validMethods are the signing algorithms accepted with published keys.
+var validMethods = []string{"ES256", "ES384", "ES512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+// Claims are the claims of a login token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Email     string `json:"email,omitempty"`
+	Type      string `json:"type"`
+	SessionID string `json:"sid,omitempty"`
+	// ClaimsOnly is set when the signature was not checked, see
+	// Verifier.AllowClaimsOnly.
+	ClaimsOnly bool `json:"-"`
+}
+
+// Verifier checks tokens against the key set of one portal.
+type Verifier struct {
+	// Origin identifies the portal in the cache and in errors.
+	Origin string
+	// Fetch downloads the key set, usually api.Client.KeySet.
+	Fetch func(ctx context.Context) (*types.KeySet, error)
+	// CacheDir holds the cached key sets. Without it keys are fetched on
+	// every run.
+	CacheDir string
+	// Leeway defaults to DefaultLeeway.
+	Leeway time.Duration
+	// AllowClaimsOnly accepts HS256 tokens while the portal publishes no
+	// keys. Such tokens are signed with the portal's shared secret, so only
+	// their claims can be checked and a forged one passes until it reaches
+	// the portal.
+	AllowClaimsOnly bool
+}
+
+// Verify checks the signature, issuer, audience and expiry of a token and
+// that it is of the given type ("access" or "refresh"). Errors match
+// ErrExpired, ErrInvalid, ErrWrongServer, ErrUnknownKey or ErrUnverifiable;
+// any other error means the keys could not be fetched. With ErrExpired the
+// claims are returned as well.
+//
+// While the portal publishes no keys, because it signs with its shared
+// secret (HS256), signatures cannot be checked locally. Such tokens are only
+// accepted with AllowClaimsOnly. Once it publishes keys, HS256 tokens fail
+// with ErrUnknownKey, so a forged one cannot pass as a token from before the
+// switch.
+func (v *Verifier) Verify(ctx context.Context, token, tokenType string) (*Claims, error) {
+	set, fresh, err := v.keySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	header, err := parseHeader(token, claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	switch {
+	case len(set.Keys) == 0 && header.Alg == "HS256":
+		if !v.AllowClaimsOnly {
+			return nil, fmt.Errorf("%w: signed with HS256 and %s publishes no keys", ErrUnverifiable, v.Origin)
+		}
+		claims.ClaimsOnly = true
+		err = jwt.NewValidator(v.options(set)...).Validate(claims)
+	case len(set.Keys) == 0:
+		return nil, fmt.Errorf("%w: signed with %s, but %s publishes no keys", ErrWrongServer, header.Alg, v.Origin)
+	case header.Alg == "HS256":
+		// Signed with the shared secret the portal used before its keys.
+		return nil, fmt.Errorf("%w: signed with HS256, but %s publishes signing keys", ErrUnknownKey, v.Origin)
+	default:
+		if _, ok := findKey(set, header.Kid); !ok && !fresh {
+			// The portal may have rotated its key since the cache was filled.
+			if set, _, err = v.keySet(ctx, true); err != nil {
+				return nil, err
+			}
+		}
+		var unknownKey error
+		_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+			key, ok := findKey(set, kid)
+			if !ok {
+				unknownKey = fmt.Errorf("%w: signed with key %q, which %s does not publish", ErrUnknownKey, kid, v.Origin)
+				return nil, unknownKey
+			}
+			if key.Alg != "" && key.Alg != t.Method.Alg() {
+				return nil, fmt.Errorf("key %q is for %s, not %s", kid, key.Alg, t.Method.Alg())
+			}
+			return parseKey(key)
+		}, append(v.options(set), jwt.WithValidMethods(validMethods))...)
+		if unknownKey != nil {
+			return nil, unknownKey
+		}
+	}
+
+	if err := classify(err, claims, set); err != nil {
+		if errors.Is(err, ErrExpired) {
+			return claims, err
+		}
+		return nil, err
+	}
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%w: expected a %s token, got %q", ErrInvalid, tokenType, claims.Type)
+	}
+	return claims, nil
+}
+
+func (v *Verifier) options(set *types.KeySet) []jwt.ParserOption {
+	leeway := v.Leeway
+	if leeway == 0 {
+		leeway = DefaultLeeway
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithIssuer(set.Issuer),
+		jwt.WithLeeway(leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if set.Audience != "" {
+		opts = append(opts, jwt.WithAudience(set.Audience))
+	}
+	return opts
+}
+
+// parseHeader decodes a token without checking it.
+func parseHeader(token string, claims *Claims) (header struct{ Alg, Kid string }, err error) {
+	t, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	if err != nil {
+		return header, err
+	}
+	header.Alg = t.Method.Alg()
+	header.Kid, _ = t.Header["kid"].(string)
+	return header, nil
+}
+
+// classify maps the errors of the jwt package to ErrExpired, ErrInvalid and
+// ErrWrongServer. A token from another portal is reported as such even when
+// it has also expired.
+func classify(err error, claims *Claims, set *types.KeySet) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return fmt.Errorf("%w: issued by %q, expected %q", ErrWrongServer, claims.Issuer, set.Issuer)
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return fmt.Errorf("%w: meant for %q, expected %q", ErrWrongServer, claims.Audience, set.Audience)
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrExpired
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+}
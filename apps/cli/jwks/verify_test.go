@@ -0,0 +1,351 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testOrigin   = "https://portal.example"
+	testAudience = "grape-cli"
+)
+
+type testKey struct {
+	kid string
+	key *ecdsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, key: key}
+}
+
+func (k testKey) jwk() types.JSONWebKey {
+	return types.JSONWebKey{
+		Kty: "EC",
+		Kid: k.kid,
+		Alg: "ES256",
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(k.key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(k.key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func (k testKey) sign(t *testing.T, claims Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func keySetOf(keys ...testKey) *types.KeySet {
+	set := &types.KeySet{Issuer: testOrigin, Audience: testAudience, Keys: []types.JSONWebKey{}}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	return set
+}
+
+// claims returns access token claims that expire in an hour, changed by
+// edit.
+func claims(edit func(*Claims)) Claims {
+	now := time.Now()
+	c := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testOrigin,
+			Audience:  jwt.ClaimStrings{testAudience},
+			Subject:   "user-1",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Type: "access",
+	}
+	if edit != nil {
+		edit(&c)
+	}
+	return c
+}
+
+// portal serves a key set and counts how often it is fetched.
+type portal struct {
+	set     *types.KeySet
+	err     error
+	fetches int
+}
+
+func (p *portal) fetch(ctx context.Context) (*types.KeySet, error) {
+	p.fetches++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.set, nil
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(t, "k1")
+	other := newTestKey(t, "k2")
+	forged := newTestKey(t, "k1")
+	ago := func(d time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(time.Now().Add(-d)) }
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{
+			name:  "valid",
+			token: key.sign(t, claims(nil)),
+		},
+		{
+			name:  "expired",
+			token: key.sign(t, claims(func(c *Claims) { c.ExpiresAt = ago(2 * time.Minute) })),
+			want:  ErrExpired,
+		},
+		{
+			name:  "expired within the leeway",
+			token: key.sign(t, claims(func(c *Claims) { c.ExpiresAt = ago(30 * time.Second) })),
+		},
+		{
+			name:  "issued slightly in the future",
+			token: key.sign(t, claims(func(c *Claims) { c.IssuedAt = ago(-30 * time.Second) })),
+		},
+		{
+			name:  "not yet valid beyond the leeway",
+			token: key.sign(t, claims(func(c *Claims) { c.NotBefore = ago(-5 * time.Minute) })),
+			want:  ErrInvalid,
+		},
+		{
+			name:  "without expiry",
+			token: key.sign(t, claims(func(c *Claims) { c.ExpiresAt = nil })),
+			want:  ErrInvalid,
+		},
+		{
+			name:  "wrong type",
+			token: key.sign(t, claims(func(c *Claims) { c.Type = "refresh" })),
+			want:  ErrInvalid,
+		},
+		{
+			name:  "bad signature",
+			token: forged.sign(t, claims(nil)),
+			want:  ErrInvalid,
+		},
+		{
+			name:  "malformed",
+			token: "not-a-token",
+			want:  ErrInvalid,
+		},
+		{
+			name:  "wrong issuer",
+			token: key.sign(t, claims(func(c *Claims) { c.Issuer = "https://other.example" })),
+			want:  ErrWrongServer,
+		},
+		{
+			name:  "wrong audience",
+			token: key.sign(t, claims(func(c *Claims) { c.Audience = jwt.ClaimStrings{"web"} })),
+			want:  ErrWrongServer,
+		},
+		{
+			name: "expired from another portal",
+			token: key.sign(t, claims(func(c *Claims) {
+				c.Issuer = "https://other.example"
+				c.ExpiresAt = ago(time.Hour)
+			})),
+			want: ErrWrongServer,
+		},
+		{
+			name:  "unknown key",
+			token: other.sign(t, claims(nil)),
+			want:  ErrUnknownKey,
+		},
+		{
+			name:  "HS256 while keys are published",
+			token: signHS256(t, claims(nil)),
+			want:  ErrUnknownKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &portal{set: keySetOf(key)}
+			v := &Verifier{Origin: testOrigin, Fetch: p.fetch}
+
+			got, err := v.Verify(context.Background(), tt.token, "access")
+			if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+			switch {
+			case err == nil || errors.Is(err, ErrExpired):
+				if got == nil || got.Subject != "user-1" {
+					t.Errorf("Verify() claims = %+v, want the claims of the token", got)
+				}
+			case got != nil:
+				t.Errorf("Verify() claims = %+v, want nil", got)
+			}
+		})
+	}
+}
+
+func signHS256(t *testing.T, c Claims) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyWithoutKeys(t *testing.T) {
+	key := newTestKey(t, "k1")
+	p := &portal{set: keySetOf()}
+
+	v := &Verifier{Origin: testOrigin, Fetch: p.fetch}
+	if _, err := v.Verify(context.Background(), signHS256(t, claims(nil)), "access"); !errors.Is(err, ErrUnverifiable) {
+		t.Errorf("Verify() of HS256 error = %v, want %v", err, ErrUnverifiable)
+	}
+
+	v.AllowClaimsOnly = true
+	got, err := v.Verify(context.Background(), signHS256(t, claims(nil)), "access")
+	if err != nil {
+		t.Fatalf("Verify() of HS256 with AllowClaimsOnly error = %v", err)
+	}
+	if !got.ClaimsOnly {
+		t.Error("Verify() of HS256 did not set ClaimsOnly")
+	}
+
+	expired := claims(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) })
+	if _, err := v.Verify(context.Background(), signHS256(t, expired), "access"); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify() of expired HS256 error = %v, want %v", err, ErrExpired)
+	}
+	if _, err := v.Verify(context.Background(), key.sign(t, claims(nil)), "access"); !errors.Is(err, ErrWrongServer) {
+		t.Errorf("Verify() of ES256 error = %v, want %v", err, ErrWrongServer)
+	}
+}
+
+func TestVerifyRefetchesForUnknownKey(t *testing.T) {
+	oldKey := newTestKey(t, "k1")
+	newKey := newTestKey(t, "k2")
+	p := &portal{set: keySetOf(oldKey)}
+	v := &Verifier{Origin: testOrigin, Fetch: p.fetch, CacheDir: t.TempDir()}
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, oldKey.sign(t, claims(nil)), "access"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(ctx, oldKey.sign(t, claims(nil)), "access"); err != nil {
+		t.Fatal(err)
+	}
+	if p.fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 with a cached key set", p.fetches)
+	}
+
+	// The portal rotates its key.
+	p.set = keySetOf(newKey)
+	if _, err := v.Verify(ctx, newKey.sign(t, claims(nil)), "access"); err != nil {
+		t.Fatalf("Verify() with a rotated key error = %v", err)
+	}
+	if p.fetches != 2 {
+		t.Fatalf("fetches = %d, want 2 after an unknown key", p.fetches)
+	}
+
+	// The retired key stays unknown after one more fetch.
+	if _, err := v.Verify(ctx, oldKey.sign(t, claims(nil)), "access"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Verify() with a retired key error = %v, want %v", err, ErrUnknownKey)
+	}
+	if p.fetches != 3 {
+		t.Errorf("fetches = %d, want 3", p.fetches)
+	}
+}
+
+func TestVerifyUnknownKeyFetchesOnce(t *testing.T) {
+	p := &portal{set: keySetOf(newTestKey(t, "k1"))}
+	v := &Verifier{Origin: testOrigin, Fetch: p.fetch, CacheDir: t.TempDir()}
+
+	token := newTestKey(t, "k2").sign(t, claims(nil))
+	if _, err := v.Verify(context.Background(), token, "access"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrUnknownKey)
+	}
+	if p.fetches != 1 {
+		t.Errorf("fetches = %d, want 1 when the key set was just fetched", p.fetches)
+	}
+}
+
+func TestVerifyStaleCache(t *testing.T) {
+	key := newTestKey(t, "k1")
+	token := key.sign(t, claims(nil))
+	ctx := context.Background()
+
+	stale := cachedKeySet{Origin: testOrigin, FetchedAt: time.Now().Add(-2 * cacheTTL), KeySet: *keySetOf(key)}
+
+	t.Run("portal unreachable", func(t *testing.T) {
+		p := &portal{err: errors.New("connection refused")}
+		v := &Verifier{Origin: testOrigin, Fetch: p.fetch, CacheDir: t.TempDir()}
+		if err := v.writeCache(stale); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := v.Verify(ctx, token, "access"); err != nil {
+			t.Errorf("Verify() with a stale cache error = %v", err)
+		}
+		if p.fetches != 1 {
+			t.Errorf("fetches = %d, want 1", p.fetches)
+		}
+	})
+
+	t.Run("portal reachable", func(t *testing.T) {
+		rotated := newTestKey(t, "k2")
+		p := &portal{set: keySetOf(rotated)}
+		v := &Verifier{Origin: testOrigin, Fetch: p.fetch, CacheDir: t.TempDir()}
+		if err := v.writeCache(stale); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := v.Verify(ctx, rotated.sign(t, claims(nil)), "access"); err != nil {
+			t.Errorf("Verify() error = %v, want the stale cache replaced", err)
+		}
+		cached, err := v.readCache()
+		if err != nil || time.Since(cached.FetchedAt) > time.Minute {
+			t.Errorf("readCache() = %+v, %v, want a fresh cache", cached, err)
+		}
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		p := &portal{err: errors.New("connection refused")}
+		v := &Verifier{Origin: testOrigin, Fetch: p.fetch, CacheDir: t.TempDir()}
+
+		_, err := v.Verify(ctx, token, "access")
+		if err == nil || errors.Is(err, ErrInvalid) || errors.Is(err, ErrWrongServer) || errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Verify() error = %v, want a fetch error", err)
+		}
+	})
+
+	t.Run("cache of another portal", func(t *testing.T) {
+		p := &portal{err: errors.New("connection refused")}
+		dir := t.TempDir()
+		other := &Verifier{Origin: testOrigin, CacheDir: dir}
+		if err := other.writeCache(cachedKeySet{Origin: "https://other.example", FetchedAt: time.Now(), KeySet: *keySetOf(key)}); err != nil {
+			t.Fatal(err)
+		}
+
+		v := &Verifier{Origin: testOrigin, Fetch: p.fetch, CacheDir: dir}
+		if _, err := v.Verify(ctx, token, "access"); err == nil {
+			t.Error("Verify() used the cached keys of another portal")
+		}
+	})
+}
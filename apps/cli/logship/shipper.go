@@ -0,0 +1,363 @@
+package logship
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/api"
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+	"github.com/imroc/req/v3"
+)
+
+// Log levels accepted by the deployment_logs table.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+const (
+	defaultFlushInterval = 500 * time.Millisecond
+	defaultMaxBatch      = 200
+	defaultRetries       = 3
+	retryBaseDelay       = 200 * time.Millisecond
+	maxBackoff           = 30 * time.Second
+)
+
+// Entry is a single row for the deployment_logs table.
+type Entry struct {
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	Step      string    `json:"step,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Config controls a Shipper.
+type Config struct {
+	// URL is the logs endpoint, e.g. https://portal/api/deployments/{id}/logs.
+	URL string
+	// Tokens supplies the bearer token sent with every batch. It is asked
+	// for a token per batch and refreshed when the server answers 401, so
+	// shipping outlives the access token during long applies.
+	Tokens api.TokenSource
+	// SpoolFile persists batches that could not be delivered. Entries left
+	// in it by an earlier run are shipped before new ones. Disabled when empty.
+	SpoolFile string
+	// FlushInterval is how often buffered lines are sent. Defaults to 500ms.
+	FlushInterval time.Duration
+	// MaxBatch caps the number of entries per request. Defaults to 200.
+	MaxBatch int
+	// Logf reports delivery problems. Defaults to log.Printf.
+	Logf func(format string, args ...any)
+	// Reveal ships messages as they are. By default tokens in them are
+	// masked with redact.String, before they reach the spool file.
+	Reveal bool
+	// Version is the CLI version reported in the User-Agent header.
+	Version string
+}
+
+// Shipper buffers log entries and ships them in order, in batches, from a
+// background goroutine. When the server is unreachable, batches are spooled
+// to disk and delivered once it comes back, before any newer entries.
+type Shipper struct {
+	cfg    Config
+	client *req.Client
+
+	mu      sync.Mutex
+	pending []Entry
+
+	// Only touched by the shipping goroutine (and Close after it exits).
+	spooled   bool
+	failures  int
+	nextRetry time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New starts a shipper. Close must be called to flush the remaining entries.
+func New(cfg Config) *Shipper {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = defaultMaxBatch
+	}
+	if cfg.Logf == nil {
+		cfg.Logf = log.Printf
+	}
+
+	s := &Shipper{
+		cfg:    cfg,
+		client: req.C().SetTimeout(10 * time.Second).SetUserAgent(api.UserAgent(cfg.Version)),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if cfg.SpoolFile != "" {
+		if info, err := os.Stat(cfg.SpoolFile); err == nil && info.Size() > 0 {
+			s.spooled = true
+		}
+	}
+
+	go s.loop()
+	return s
+}
+
+// SpoolPath returns the default spool file for a deployment under the user's
+// cache directory.
+func SpoolPath(deploymentID string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := unsafeFileChars.ReplaceAllString(deploymentID, "_")
+	return filepath.Join(cacheDir, "grape", "logspool", name+".jsonl"), nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Log queues a single entry.
+func (s *Shipper) Log(level, step, message string) {
+	if !s.cfg.Reveal {
+		message = redact.String(message)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, Entry{Level: level, Message: message, Step: step, CreatedAt: time.Now().UTC()})
+}
+
+// Close stops the background goroutine and makes a final attempt to deliver
+// everything, retrying until ctx is done. Entries that still could not be
+// delivered stay in the spool file and an error is returned.
+func (s *Shipper) Close(ctx context.Context) error {
+	close(s.stop)
+	<-s.done
+
+	s.nextRetry = time.Time{}
+	for {
+		err := s.flush(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if s.cfg.SpoolFile != "" {
+				return fmt.Errorf("logs not delivered, kept in %s: %w", s.cfg.SpoolFile, err)
+			}
+			return fmt.Errorf("logs not delivered: %w", err)
+		case <-time.After(s.backoff()):
+			s.nextRetry = time.Time{}
+		}
+	}
+}
+
+func (s *Shipper) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.flush(context.Background())
+		}
+	}
+}
+
+// flush delivers the spool and then the pending entries. While the server is
+// failing, new entries go straight to the spool so ordering is preserved.
+func (s *Shipper) flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if time.Now().Before(s.nextRetry) {
+		return s.spoolOrRequeue(batch, errors.New("waiting to retry"))
+	}
+
+	if s.spooled {
+		if err := s.drainSpool(ctx); err != nil {
+			return s.spoolOrRequeue(batch, s.failed(err))
+		}
+	}
+
+	for len(batch) > 0 {
+		n := min(len(batch), s.cfg.MaxBatch)
+		if err := s.sendWithRetry(ctx, batch[:n]); err != nil && !s.dropRejected(err, n) {
+			return s.spoolOrRequeue(batch, s.failed(err))
+		}
+		batch = batch[n:]
+	}
+
+	s.failures = 0
+	return nil
+}
+
+// failed records a delivery failure and schedules the next attempt.
+func (s *Shipper) failed(err error) error {
+	s.failures++
+	s.nextRetry = time.Now().Add(s.backoff())
+	if s.failures == 1 {
+		s.cfg.Logf("Log shipping failed, buffering: %v", err)
+	}
+	return err
+}
+
+func (s *Shipper) backoff() time.Duration {
+	d := retryBaseDelay << min(s.failures, 8)
+	return min(d, maxBackoff)
+}
+
+// spoolOrRequeue keeps undelivered entries, on disk when a spool file is
+// configured and in memory (ahead of newer entries) otherwise.
+func (s *Shipper) spoolOrRequeue(batch []Entry, cause error) error {
+	if len(batch) == 0 {
+		return cause
+	}
+	if s.cfg.SpoolFile != "" {
+		err := appendSpool(s.cfg.SpoolFile, batch)
+		if err == nil {
+			s.spooled = true
+			return cause
+		}
+		s.cfg.Logf("Error spooling logs: %v", err)
+	}
+
+	s.mu.Lock()
+	s.pending = append(batch, s.pending...)
+	s.mu.Unlock()
+	return cause
+}
+
+func (s *Shipper) drainSpool(ctx context.Context) error {
+	entries, err := readSpool(s.cfg.SpoolFile)
+	if err != nil {
+		return err
+	}
+	for sent := 0; sent < len(entries); {
+		n := min(len(entries)-sent, s.cfg.MaxBatch)
+		if err := s.sendWithRetry(ctx, entries[sent:sent+n]); err != nil && !s.dropRejected(err, n) {
+			// Keep only what is left so delivered batches are not repeated.
+			if rerr := rewriteSpool(s.cfg.SpoolFile, entries[sent:]); rerr != nil {
+				s.cfg.Logf("Error rewriting log spool: %v", rerr)
+			}
+			return err
+		}
+		sent += n
+	}
+
+	s.spooled = false
+	return os.Remove(s.cfg.SpoolFile)
+}
+
+func (s *Shipper) sendWithRetry(ctx context.Context, batch []Entry) error {
+	var err error
+	delay := retryBaseDelay
+	for attempt := 0; attempt < defaultRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		if err = s.send(ctx, batch); err == nil {
+			return nil
+		}
+		var rejected *rejectedError
+		if errors.As(err, &rejected) {
+			return err
+		}
+	}
+	return err
+}
+
+// rejectedError is returned when the server refuses a batch outright.
+// Retrying or spooling it would block every later entry.
+type rejectedError struct {
+	status  int
+	message string
+}
+
+func (e *rejectedError) Error() string {
+	return fmt.Sprintf("server rejected logs (HTTP %d): %s", e.status, e.message)
+}
+
+// dropRejected reports whether err means the batch can never be delivered,
+// in which case it is discarded.
+func (s *Shipper) dropRejected(err error, n int) bool {
+	var rejected *rejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	s.cfg.Logf("Dropping %d log entries: %v", n, err)
+	return true
+}
+
+func (s *Shipper) send(ctx context.Context, batch []Entry) error {
+	var token string
+	if s.cfg.Tokens != nil {
+		var err error
+		if token, err = s.cfg.Tokens.Token(ctx); err != nil {
+			return fmt.Errorf("error getting a token: %w", err)
+		}
+	}
+
+	err := s.post(ctx, batch, token)
+	if errors.Is(err, errUnauthorized) && s.cfg.Tokens != nil {
+		// The token expired during the deployment; the batch is kept until
+		// a refreshed one is accepted.
+		if token, err = s.cfg.Tokens.Refresh(ctx, token); err != nil {
+			return fmt.Errorf("token rejected and not refreshed: %w", err)
+		}
+		err = s.post(ctx, batch, token)
+	}
+	return err
+}
+
+// errUnauthorized is returned by post for a 401. Unlike other client errors
+// it is not a rejectedError: the batch is fine, the token is not.
+var errUnauthorized = errors.New("server rejected the token (HTTP 401)")
+
+func (s *Shipper) post(ctx context.Context, batch []Entry, token string) error {
+	var errMsg struct {
+		Error string `json:"error"`
+	}
+	r := s.client.R().
+		SetContext(ctx).
+		SetBody(batch).
+		SetErrorResult(&errMsg)
+	if token != "" {
+		r.SetBearerAuthToken(token)
+	}
+	resp, err := r.Post(s.cfg.URL)
+	if resp == nil || resp.Response == nil {
+		return err
+	}
+	// An empty error body fails to decode; the status code is what matters.
+	if resp.IsErrorState() {
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized:
+			return errUnauthorized
+		case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+			return fmt.Errorf("server returned %d: %s", resp.StatusCode, errMsg.Error)
+		default:
+			return &rejectedError{status: resp.StatusCode, message: errMsg.Error}
+		}
+	}
+	return err
+}
@@ -0,0 +1,467 @@
+package logship
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/redact"
+)
+
+// logServer records the batches it accepts. respond, when set, picks the
+// status code for each request; requests are numbered from 0.
+type logServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int
+	batches  [][]Entry
+	tokens   []string
+	respond  func(n int, batch []Entry, token string) int
+}
+
+func newLogServer(t *testing.T, respond func(n int, batch []Entry, token string) int) *logServer {
+	s := &logServer{respond: respond}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []Entry
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("decoding batch: %v", err)
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		s.mu.Lock()
+		n := s.requests
+		s.requests++
+		status := http.StatusCreated
+		if s.respond != nil {
+			status = s.respond(n, batch, token)
+		}
+		if status < 300 {
+			s.batches = append(s.batches, batch)
+			s.tokens = append(s.tokens, token)
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+		if status >= 400 {
+			fmt.Fprintf(w, `{"error":"status %d"}`, status)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// messages returns the messages delivered so far, in order.
+func (s *logServer) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []string
+	for _, batch := range s.batches {
+		for _, entry := range batch {
+			messages = append(messages, entry.Message)
+		}
+	}
+	return messages
+}
+
+func (s *logServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *logServer) batchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sizes []int
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+// testTokens hands out token and replaces it with refreshed on Refresh.
+type testTokens struct {
+	mu        sync.Mutex
+	token     string
+	refreshed string
+	refreshes int
+}
+
+func (t *testTokens) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token, nil
+}
+
+func (t *testTokens) Refresh(ctx context.Context, rejected string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refreshes++
+	t.token = t.refreshed
+	return t.token, nil
+}
+
+// logfRecorder collects what the shipper reports.
+type logfRecorder struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *logfRecorder) logf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *logfRecorder) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.lines, "\n")
+}
+
+// newShipper returns a shipper that only sends when closed, unless cfg sets
+// a FlushInterval.
+func newShipper(t *testing.T, cfg Config) (*Shipper, *logfRecorder) {
+	logs := &logfRecorder{}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Hour
+	}
+	cfg.Logf = logs.logf
+	return New(cfg), logs
+}
+
+func logMessages(s *Shipper, messages ...string) {
+	for _, message := range messages {
+		s.Log(LevelInfo, "apply", message)
+	}
+}
+
+func closeWithin(s *Shipper, d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return s.Close(ctx)
+}
+
+func TestShipperBatchesInOrder(t *testing.T) {
+	server := newLogServer(t, nil)
+	shipper, _ := newShipper(t, Config{URL: server.URL, MaxBatch: 2, Tokens: &testTokens{token: "t1"}})
+
+	logMessages(shipper, "a", "b", "c", "d", "e")
+	if err := closeWithin(shipper, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := server.messages(), []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+	if got, want := server.batchSizes(), []int{2, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("batch sizes = %v, want %v", got, want)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	entry := server.batches[0][0]
+	if entry.Level != LevelInfo || entry.Step != "apply" || entry.CreatedAt.IsZero() {
+		t.Errorf("entry = %+v, want level, step and time set", entry)
+	}
+	if server.tokens[0] != "t1" {
+		t.Errorf("token = %q, want t1", server.tokens[0])
+	}
+}
+
+func TestShipperRedacts(t *testing.T) {
+	line := "pushing with grape_0123456789abcdefghijkl"
+	tests := []struct {
+		reveal bool
+		want   string
+	}{
+		{false, "pushing with " + redact.Mask},
+		{true, line},
+	}
+	for _, tt := range tests {
+		server := newLogServer(t, nil)
+		shipper, _ := newShipper(t, Config{URL: server.URL, Reveal: tt.reveal})
+
+		logMessages(shipper, line)
+		if err := closeWithin(shipper, 5*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if got := server.messages(); !reflect.DeepEqual(got, []string{tt.want}) {
+			t.Errorf("Reveal %v: delivered %q, want %q", tt.reveal, got, tt.want)
+		}
+	}
+}
+
+func TestShipperRetries(t *testing.T) {
+	server := newLogServer(t, func(n int, batch []Entry, token string) int {
+		if n < 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusCreated
+	})
+	shipper, _ := newShipper(t, Config{URL: server.URL})
+
+	logMessages(shipper, "a")
+	if err := closeWithin(shipper, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.messages(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("delivered %q, want [a]", got)
+	}
+	if n := server.requestCount(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestShipperRefreshesToken(t *testing.T) {
+	server := newLogServer(t, func(n int, batch []Entry, token string) int {
+		if token != "fresh" {
+			return http.StatusUnauthorized
+		}
+		return http.StatusCreated
+	})
+	tokens := &testTokens{token: "stale", refreshed: "fresh"}
+	shipper, _ := newShipper(t, Config{URL: server.URL, MaxBatch: 1, Tokens: tokens})
+
+	logMessages(shipper, "a", "b")
+	if err := closeWithin(shipper, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.messages(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("delivered %q, want [a b]", got)
+	}
+	if tokens.refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", tokens.refreshes)
+	}
+}
+
+func TestShipperDropsRejectedBatches(t *testing.T) {
+	server := newLogServer(t, func(n int, batch []Entry, token string) int {
+		if batch[0].Message == "bad" {
+			return http.StatusBadRequest
+		}
+		return http.StatusCreated
+	})
+	shipper, logs := newShipper(t, Config{URL: server.URL, MaxBatch: 1})
+
+	logMessages(shipper, "a", "bad", "c")
+	if err := closeWithin(shipper, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.messages(); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("delivered %q, want [a c]", got)
+	}
+	// A rejected batch is not retried.
+	if n := server.requestCount(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if !strings.Contains(logs.String(), "Dropping 1 log entries") {
+		t.Errorf("Logf output = %q, want the dropped batch reported", logs)
+	}
+}
+
+func TestShipperSpoolsOnFailure(t *testing.T) {
+	server := newLogServer(t, func(n int, batch []Entry, token string) int {
+		return http.StatusInternalServerError
+	})
+	spool := filepath.Join(t.TempDir(), "logspool", "d1.jsonl")
+	shipper, _ := newShipper(t, Config{URL: server.URL, SpoolFile: spool})
+
+	logMessages(shipper, "a", "b")
+	err := closeWithin(shipper, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), spool) {
+		t.Fatalf("Close() error = %v, want the spool file named", err)
+	}
+
+	entries, err := readSpool(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entryMessages(entries); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("spooled %q, want [a b]", got)
+	}
+	info, err := os.Stat(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("spool file mode = %v, want 0600", perm)
+	}
+}
+
+func TestShipperReplaysSpool(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "d1.jsonl")
+	if err := appendSpool(spool, []Entry{{Level: LevelInfo, Message: "old1"}, {Level: LevelInfo, Message: "old2"}}); err != nil {
+		t.Fatal(err)
+	}
+	server := newLogServer(t, nil)
+	shipper, _ := newShipper(t, Config{URL: server.URL, SpoolFile: spool, MaxBatch: 1})
+
+	logMessages(shipper, "new")
+	if err := closeWithin(shipper, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := server.messages(), []string{"old1", "old2", "new"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+	if _, err := os.Stat(spool); !os.IsNotExist(err) {
+		t.Errorf("spool file still exists after replay: %v", err)
+	}
+}
+
+func TestShipperKeepsUndeliveredSpool(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "d1.jsonl")
+	if err := appendSpool(spool, []Entry{{Message: "old1"}, {Message: "old2"}, {Message: "old3"}}); err != nil {
+		t.Fatal(err)
+	}
+	server := newLogServer(t, func(n int, batch []Entry, token string) int {
+		if n == 0 {
+			return http.StatusCreated
+		}
+		return http.StatusInternalServerError
+	})
+	shipper, _ := newShipper(t, Config{URL: server.URL, SpoolFile: spool, MaxBatch: 1})
+
+	logMessages(shipper, "new")
+	if err := closeWithin(shipper, 100*time.Millisecond); err == nil {
+		t.Fatal("Close() succeeded while the server fails")
+	}
+
+	if got := server.messages(); !reflect.DeepEqual(got, []string{"old1"}) {
+		t.Errorf("delivered %q, want [old1]", got)
+	}
+	entries, err := readSpool(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The delivered batch is gone, so it is not sent twice.
+	if got, want := entryMessages(entries), []string{"old2", "old3", "new"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("spooled %q, want %q", got, want)
+	}
+	if _, err := os.Stat(spool + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary spool file left behind: %v", err)
+	}
+}
+
+// While the server is down the background loop spools, and newer entries
+// are delivered only after the spooled ones.
+func TestShipperOrderAcrossOutage(t *testing.T) {
+	var mu sync.Mutex
+	up := false
+	server := newLogServer(t, func(n int, batch []Entry, token string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up {
+			return http.StatusBadGateway
+		}
+		return http.StatusCreated
+	})
+	spool := filepath.Join(t.TempDir(), "d1.jsonl")
+	shipper, logs := newShipper(t, Config{URL: server.URL, SpoolFile: spool, FlushInterval: 10 * time.Millisecond})
+
+	logMessages(shipper, "a", "b")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(spool); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("entries were not spooled while the server was down")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	logMessages(shipper, "c")
+	mu.Lock()
+	up = true
+	mu.Unlock()
+	logMessages(shipper, "d")
+
+	if err := closeWithin(shipper, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := server.messages(), []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+	if !strings.Contains(logs.String(), "Log shipping failed, buffering") {
+		t.Errorf("Logf output = %q, want the outage reported", logs)
+	}
+}
+
+func TestRewriteSpool(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "d1.jsonl")
+	if err := appendSpool(spool, []Entry{{Message: "a"}, {Message: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	// A temporary file left by an interrupted rewrite is replaced.
+	if err := os.WriteFile(spool+".tmp", []byte("garbage\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rewriteSpool(spool, []Entry{{Message: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readSpool(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entryMessages(entries); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("readSpool() = %q, want [b]", got)
+	}
+	if _, err := os.Stat(spool + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary spool file left behind: %v", err)
+	}
+}
+
+func TestReadSpoolSkipsTornLine(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "d1.jsonl")
+	if err := appendSpool(spool, []Entry{{Message: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(spool, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"level":"info","mess`)
+	file.Close()
+
+	entries, err := readSpool(spool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entryMessages(entries); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("readSpool() = %q, want [a]", got)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var lines []string
+	w := NewWriter(func(line string) { lines = append(lines, line) })
+
+	fmt.Fprint(w, "first\nsec")
+	fmt.Fprint(w, "ond  \n\n50%\r100%\rpartial")
+	if want := []string{"first", "second", "50%", "100%"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	w.Close()
+	if lines[len(lines)-1] != "partial" {
+		t.Errorf("Close() did not flush the partial line, lines = %q", lines)
+	}
+}
+
+func entryMessages(entries []Entry) []string {
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
@@ -0,0 +1,77 @@
+package logship
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Writer splits written output into lines, trimmed of trailing blanks, and
+// passes each non-empty one on. Carriage returns (progress output) also end
+// a line.
+type Writer struct {
+	emit func(line string)
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewWriter returns a Writer that calls emit for every line. Close flushes a
+// trailing partial line.
+func NewWriter(emit func(line string)) *Writer {
+	return &Writer{emit: emit}
+}
+
+// Writer returns a Writer that logs each line with the given level and step.
+func (s *Shipper) Writer(level, step string) *Writer {
+	return NewWriter(func(line string) { s.Log(level, step, line) })
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, b := range p {
+		if b == '\n' || b == '\r' {
+			w.flushLocked()
+			continue
+		}
+		w.buf.WriteByte(b)
+	}
+	return len(p), nil
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+	return nil
+}
+
+func (w *Writer) flushLocked() {
+	if line := strings.TrimRight(w.buf.String(), " \t"); line != "" {
+		w.emit(line)
+	}
+	w.buf.Reset()
+}
+
+// StepOutput captures a step's stdout as info and stderr as error entries.
+// It satisfies deploy.StderrWriter so exec'd tools have both streams tagged.
+type StepOutput struct {
+	stdout *Writer
+	stderr *Writer
+}
+
+func (s *Shipper) Step(step string) *StepOutput {
+	return &StepOutput{stdout: s.Writer(LevelInfo, step), stderr: s.Writer(LevelError, step)}
+}
+
+func (o *StepOutput) Write(p []byte) (int, error) { return o.stdout.Write(p) }
+
+func (o *StepOutput) Stderr() io.Writer { return o.stderr }
+
+func (o *StepOutput) Close() error {
+	o.stdout.Close()
+	return o.stderr.Close()
+}
@@ -2,6 +2,9 @@ package main
 
 import "github.com/bobikenobi12/bb-thesis-2026/apps/cli/cmd"
 
+// version is set at build time by goreleaser through -ldflags "-X main.version=...".
+var version = "dev"
+
 func main() {
-	cmd.Execute()
+	cmd.Execute(version)
 }
@@ -0,0 +1,141 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultName is the profile used when none is selected. It always exists.
+	DefaultName = "default"
+	// DefaultOrigin is the portal used when a profile has no origin and
+	// GRAPE_WEB_ORIGIN is not set.
+	DefaultOrigin = "https://localhost:3000"
+
+	fileName = "config.yaml"
+)
+
+// Defaults are per-profile values for command flags.
+type Defaults struct {
+	AWSProfile string `yaml:"aws_profile,omitempty"`
+}
+
+// Profile is a named server context. Its credentials live in the credential
+// store under the profile name.
+type Profile struct {
+	Origin   string   `yaml:"origin,omitempty"`
+	Defaults Defaults `yaml:"defaults,omitempty"`
+}
+
+// Config is the CLI's config.yaml.
+type Config struct {
+	Current  string              `yaml:"current_profile,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+
+	path string
+}
+
+// Load reads config.yaml from dir. A missing file yields an empty config.
+func Load(dir string) (*Config, error) {
+	path := filepath.Join(dir, fileName)
+	cfg := &Config{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		cfg.Profiles = map[string]*Profile{}
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// Save writes the config back to the file it was loaded from.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// CurrentName returns the selected profile, falling back to the default.
+func (c *Config) CurrentName() string {
+	if c.Current == "" {
+		return DefaultName
+	}
+	return c.Current
+}
+
+// Get returns a profile by name. The default profile exists even when it has
+// not been written to the file.
+func (c *Config) Get(name string) (*Profile, bool) {
+	if p, ok := c.Profiles[name]; ok {
+		return p, true
+	}
+	if name == DefaultName {
+		return &Profile{}, true
+	}
+	return nil, false
+}
+
+// Names returns all profile names, sorted, including the default profile.
+func (c *Config) Names() []string {
+	names := []string{}
+	if _, ok := c.Profiles[DefaultName]; !ok {
+		names = append(names, DefaultName)
+	}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveOrigin returns the portal URL for a profile: its own origin, then
+// GRAPE_WEB_ORIGIN, then DefaultOrigin.
+func (p *Profile) ResolveOrigin() string {
+	if p.Origin != "" {
+		return p.Origin
+	}
+	if origin := os.Getenv("GRAPE_WEB_ORIGIN"); origin != "" {
+		return origin
+	}
+	return DefaultOrigin
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateName checks that a profile name is safe to use in file and keyring names.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ValidateOrigin checks that an origin is an absolute http(s) URL.
+func ValidateOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid origin %q: expected a URL like https://portal.example.com", origin)
+	}
+	return nil
+}
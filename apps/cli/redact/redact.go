@@ -0,0 +1,180 @@
+// Package redact masks credentials before they are printed, written to files
+// or shipped. Values are recognised by the name of the field holding them,
+// like gitops_app_token or refresh_token, and by the shape of well-known
+// tokens anywhere in a string.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mask replaces a redacted value.
+const Mask = "[REDACTED]"
+
+// sensitiveNames are parts of field names whose values are credentials,
+// compared without underscores and dashes.
+var sensitiveNames = []string{
+	"token",
+	"secret",
+	"password",
+	"passwd",
+	"privatekey",
+	"apikey",
+	"accesskey",
+	"credential",
+	"authorization",
+	"cookie",
+}
+
+// describingSuffixes end names that describe a credential without holding
+// it, like token_prefix, token_type or secret_id.
+var describingSuffixes = []string{
+	"prefix",
+	"type",
+	"id",
+	"expiresat",
+	"expiresin",
+}
+
+// patterns match tokens by their shape. Matches are replaced by repl, which
+// may keep a label such as "Bearer " in front of the mask.
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// JWT: a base64url header starting with {" and two more segments.
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]+`), Mask},
+	// AWS access key IDs.
+	{regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA)[0-9A-Z]{16}\b`), Mask},
+	// AWS secret access keys, when labelled as such.
+	{regexp.MustCompile(`(?i)((?:aws_)?secret_?access_?key["']?\s*[:=]\s*["']?)[A-Za-z0-9/+=]{40,}`), "${1}" + Mask},
+	// GitHub personal access, OAuth, app and refresh tokens.
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), Mask},
+	{regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`), Mask},
+	// Passwords in URLs, e.g. https://x-access-token:<token>@github.com.
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`), "${1}" + Mask + "@"},
+	// Grape API tokens.
+	{regexp.MustCompile(`\bgrape_[A-Za-z0-9_-]{20,}`), Mask},
+	// Bearer tokens, e.g. in a logged Authorization header.
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]{16,}`), "${1}" + Mask},
+}
+
+// Key reports whether a field with this name holds a credential, e.g.
+// gitops_argocd_token or RefreshToken.
+func Key(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, s := range describingSuffixes {
+		if strings.HasSuffix(name, s) {
+			return false
+		}
+	}
+	for _, s := range sensitiveNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks the tokens found in s.
+func String(s string) string {
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// Value masks the value of the named field: entirely when the name is
+// sensitive, and the tokens in it otherwise. Empty values stay empty so it
+// remains visible whether a secret is set.
+func Value(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if Key(name) {
+		return Mask
+	}
+	return String(value)
+}
+
+// Node masks a decoded YAML or JSON document in place. Strings under a
+// sensitive key, at any depth, are masked; other strings have their tokens
+// masked. Booleans and numbers are kept.
+func Node(n *yaml.Node) {
+	node(n, false)
+}
+
+func node(n *yaml.Node, sensitive bool) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			node(c, sensitive)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			node(n.Content[i+1], sensitive || Key(n.Content[i].Value))
+		}
+	case yaml.ScalarNode:
+		switch {
+		case n.Tag != "!!str" || n.Value == "":
+		case sensitive:
+			n.Value = Mask
+		default:
+			n.Value = String(n.Value)
+		}
+	}
+}
+
+// Restore puts back the values that were masked in desired, an edited copy
+// of current, both decoded from JSON. A masked value is replaced by the value
+// at the same path in current. The paths of masked values current has no
+// value for are returned.
+func Restore(desired, current any) []string {
+	var missing []string
+	restore("", desired, current, &missing)
+	return missing
+}
+
+func restore(path string, desired, current any, missing *[]string) {
+	switch d := desired.(type) {
+	case map[string]any:
+		c, _ := current.(map[string]any)
+		for k, v := range d {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if v == Mask {
+				if old, ok := c[k]; ok && old != nil {
+					d[k] = old
+				} else {
+					*missing = append(*missing, p)
+				}
+				continue
+			}
+			restore(p, v, c[k], missing)
+		}
+	case []any:
+		c, _ := current.([]any)
+		for i, v := range d {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			var old any
+			if i < len(c) {
+				old = c[i]
+			}
+			if v == Mask {
+				if old != nil {
+					d[i] = old
+				} else {
+					*missing = append(*missing, p)
+				}
+				continue
+			}
+			restore(p, v, old, missing)
+		}
+	}
+}
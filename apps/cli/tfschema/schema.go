@@ -0,0 +1,120 @@
+package tfschema
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+//go:generate cp ../../../packages/templates/variables.tf variables.tf
+
+// templateVariables is a copy of the infrastructure template's variables.tf.
+//
+//go:embed variables.tf
+var templateVariables []byte
+
+// Variable is a `variable` block of variables.tf.
+type Variable struct {
+	Name        string
+	Description string
+	Type        cty.Type
+	// Defaults holds the optional(..., default) values of object attributes.
+	Defaults *typeexpr.Defaults
+	// Default is cty.NilVal for required variables.
+	Default cty.Value
+}
+
+// Required reports whether the variable has no default.
+func (v *Variable) Required() bool {
+	return v.Default == cty.NilVal
+}
+
+// Schema is the set of variables declared by a template, in file order.
+type Schema struct {
+	Variables []*Variable
+	byName    map[string]*Variable
+}
+
+// Template returns the schema of the bundled infrastructure template.
+func Template() (*Schema, error) {
+	return Parse(templateVariables, "variables.tf")
+}
+
+// Lookup returns a declared variable by name.
+func (s *Schema) Lookup(name string) (*Variable, bool) {
+	v, ok := s.byName[name]
+	return v, ok
+}
+
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "variable", LabelNames: []string{"name"}}},
+}
+
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+		{Name: "default"},
+		{Name: "description"},
+	},
+}
+
+// Parse reads the variable declarations of a Terraform file. Other blocks
+// and variable arguments such as validation are ignored.
+func Parse(src []byte, filename string) (*Schema, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, _, diags := file.Body.PartialContent(fileSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	s := &Schema{byName: map[string]*Variable{}}
+	for _, block := range content.Blocks {
+		v, err := parseVariable(block)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.byName[v.Name]; ok {
+			return nil, fmt.Errorf("%s: variable %q is declared twice", block.DefRange, v.Name)
+		}
+		s.Variables = append(s.Variables, v)
+		s.byName[v.Name] = v
+	}
+	return s, nil
+}
+
+func parseVariable(block *hcl.Block) (*Variable, error) {
+	content, _, diags := block.Body.PartialContent(variableSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	v := &Variable{Name: block.Labels[0], Type: cty.DynamicPseudoType}
+
+	if attr, ok := content.Attributes["type"]; ok {
+		ty, defaults, diags := typeexpr.TypeConstraintWithDefaults(attr.Expr)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		v.Type, v.Defaults = ty, defaults
+	}
+	if attr, ok := content.Attributes["description"]; ok {
+		if val, diags := attr.Expr.Value(nil); !diags.HasErrors() && val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+			v.Description = val.AsString()
+		}
+	}
+	if attr, ok := content.Attributes["default"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		v.Default = val
+	}
+	return v, nil
+}
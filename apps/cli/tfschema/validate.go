@@ -0,0 +1,333 @@
+package tfschema
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Problem is a single validation finding.
+type Problem struct {
+	// Path locates the value, e.g. eks_cluster_admins[0].username.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Warning problems do not make the values invalid.
+	Warning bool `json:"warning,omitempty"`
+	// Range is the assignment in the values file the problem belongs to.
+	Range hcl.Range `json:"-"`
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// HasErrors reports whether any of the problems is not a warning.
+func HasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if !p.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// Size limits AWS puts on VPC CIDR blocks.
+const (
+	minVPCPrefix = 16
+	maxVPCPrefix = 28
+)
+
+type checker struct {
+	values   map[string]Value
+	problems []Problem
+}
+
+func (c *checker) add(path, format string, args ...any) {
+	c.problems = append(c.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Range: c.rangeOf(path)})
+}
+
+func (c *checker) warn(path, format string, args ...any) {
+	c.problems = append(c.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Warning: true, Range: c.rangeOf(path)})
+}
+
+// rangeOf returns the assignment of the variable a path starts with.
+func (c *checker) rangeOf(path string) hcl.Range {
+	name, _, _ := strings.Cut(strings.SplitN(path, "[", 2)[0], ".")
+	return c.values[name].Range
+}
+
+// Validate checks values against the declared variables: every required
+// variable is set, values convert to the declared types, CIDR blocks are
+// well formed and do not overlap, and min/desired/max sizes are ordered.
+// Assignments to undeclared variables are reported as warnings.
+func (s *Schema) Validate(values map[string]Value) []Problem {
+	c := &checker{values: values}
+
+	// Effective values after type conversion and defaults, for the checks
+	// that look at more than one variable.
+	resolved := map[string]cty.Value{}
+
+	for _, v := range s.Variables {
+		assigned, ok := values[v.Name]
+		if !ok {
+			if v.Required() {
+				c.add(v.Name, "required variable is not set")
+			} else {
+				resolved[v.Name] = v.Default
+			}
+			continue
+		}
+
+		val := assigned.Value
+		if v.Defaults != nil {
+			val = v.Defaults.Apply(val)
+		}
+		converted, err := convert.Convert(val, v.Type)
+		if err != nil {
+			path, err := conversionError(val, v.Type, nil, err)
+			c.add(v.Name+formatPath(path), "%s", err)
+			continue
+		}
+		if v.Required() && isEmptyString(converted) {
+			c.add(v.Name, "required variable is empty")
+			continue
+		}
+		resolved[v.Name] = converted
+	}
+
+	for _, name := range sortedNames(values) {
+		if _, ok := s.Lookup(name); !ok {
+			c.warn(name, "variable is not declared in variables.tf and will be ignored")
+		}
+	}
+
+	for _, v := range s.Variables {
+		if val, ok := resolved[v.Name]; ok && strings.Contains(v.Name, "cidr") {
+			c.checkCIDRs(v.Name, val)
+		}
+	}
+	c.checkVPC(resolved)
+	c.checkSizes(s, resolved)
+	return c.problems
+}
+
+// checkCIDRs validates a CIDR string or a list of them. Entries of a list
+// must not overlap each other.
+func (c *checker) checkCIDRs(name string, val cty.Value) {
+	if !val.IsKnown() || val.IsNull() {
+		return
+	}
+
+	ty := val.Type()
+	if ty == cty.String {
+		if val.AsString() != "" {
+			c.parseCIDR(name, val.AsString())
+		}
+		return
+	}
+	if !ty.IsListType() && !ty.IsSetType() && !ty.IsTupleType() {
+		return
+	}
+
+	type entry struct {
+		path   string
+		prefix netip.Prefix
+	}
+	var seen []entry
+	for i, el := range val.AsValueSlice() {
+		path := fmt.Sprintf("%s[%d]", name, i)
+		if !el.IsKnown() || el.IsNull() || el.Type() != cty.String {
+			c.add(path, "a CIDR string is required")
+			continue
+		}
+		prefix, ok := c.parseCIDR(path, el.AsString())
+		if !ok {
+			continue
+		}
+		for _, prev := range seen {
+			if prev.prefix.Overlaps(prefix) {
+				c.add(path, "%s overlaps %s (%s)", prefix, prev.path, prev.prefix)
+			}
+		}
+		seen = append(seen, entry{path, prefix})
+	}
+}
+
+func (c *checker) parseCIDR(path, s string) (netip.Prefix, bool) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		c.add(path, "%q is not a valid CIDR block", s)
+		return netip.Prefix{}, false
+	}
+	if masked := prefix.Masked(); masked != prefix {
+		c.add(path, "%q has host bits set, did you mean %s?", s, masked)
+		return masked, true
+	}
+	return prefix, true
+}
+
+// checkVPC validates vpc_cidr when the template creates the VPC.
+func (c *checker) checkVPC(resolved map[string]cty.Value) {
+	provision, ok := resolved["provision_vpc"]
+	if !ok || provision.Type() != cty.Bool || provision.IsNull() || !provision.True() {
+		return
+	}
+	cidr, ok := resolved["vpc_cidr"]
+	if !ok || cidr.Type() != cty.String || cidr.IsNull() {
+		return
+	}
+	if cidr.AsString() == "" {
+		c.add("vpc_cidr", "required when provision_vpc is true")
+		return
+	}
+	prefix, err := netip.ParsePrefix(cidr.AsString())
+	if err != nil {
+		return // already reported by checkCIDRs
+	}
+	prefix = prefix.Masked()
+	if !prefix.Addr().Is4() || prefix.Bits() < minVPCPrefix || prefix.Bits() > maxVPCPrefix {
+		c.add("vpc_cidr", "%s must be an IPv4 block between /%d and /%d", prefix, minVPCPrefix, maxVPCPrefix)
+	}
+}
+
+// checkSizes enforces min <= desired <= max for every <prefix>_min_size
+// variable and for objects with min_capacity and max_capacity attributes.
+func (c *checker) checkSizes(s *Schema, resolved map[string]cty.Value) {
+	for _, v := range s.Variables {
+		prefix, ok := strings.CutSuffix(v.Name, "_min_size")
+		if !ok {
+			continue
+		}
+		minName, desiredName, maxName := v.Name, prefix+"_desired_size", prefix+"_max_size"
+		lo, hasMin := number(resolved[minName])
+		desired, hasDesired := number(resolved[desiredName])
+		hi, hasMax := number(resolved[maxName])
+
+		if hasMin && lo.Sign() < 0 {
+			c.add(minName, "must not be negative")
+		}
+		if hasMin && hasMax && hi.Cmp(lo) < 0 {
+			c.add(maxName, "%s is less than %s (%s)", hi.Text('f', -1), minName, lo.Text('f', -1))
+		}
+		if hasMin && hasDesired && desired.Cmp(lo) < 0 {
+			c.add(desiredName, "%s is less than %s (%s)", desired.Text('f', -1), minName, lo.Text('f', -1))
+		}
+		if hasMax && hasDesired && desired.Cmp(hi) > 0 {
+			c.add(desiredName, "%s is greater than %s (%s)", desired.Text('f', -1), maxName, hi.Text('f', -1))
+		}
+	}
+
+	for _, v := range s.Variables {
+		val, ok := resolved[v.Name]
+		if !ok || !val.IsKnown() || val.IsNull() || !val.Type().IsObjectType() {
+			continue
+		}
+		ty := val.Type()
+		if !ty.HasAttribute("min_capacity") || !ty.HasAttribute("max_capacity") {
+			continue
+		}
+		lo, hasMin := number(val.GetAttr("min_capacity"))
+		hi, hasMax := number(val.GetAttr("max_capacity"))
+		if hasMin && hasMax && hi.Cmp(lo) < 0 {
+			c.add(v.Name+".max_capacity", "%s is less than min_capacity (%s)", hi.Text('f', -1), lo.Text('f', -1))
+		}
+	}
+}
+
+func number(val cty.Value) (*big.Float, bool) {
+	if val == cty.NilVal || !val.IsKnown() || val.IsNull() || val.Type() != cty.Number {
+		return nil, false
+	}
+	return val.AsBigFloat(), true
+}
+
+func isEmptyString(val cty.Value) bool {
+	return val.Type() == cty.String && val.IsKnown() && !val.IsNull() && strings.TrimSpace(val.AsString()) == ""
+}
+
+// conversionError locates the value that made converting val to want fail.
+// convert reports a mismatch inside a collection as a single message for
+// the whole value, so this descends into the elements to find the one that
+// does not convert.
+func conversionError(val cty.Value, want cty.Type, path cty.Path, err error) (cty.Path, error) {
+	var pathErr cty.PathError
+	if errors.As(err, &pathErr) {
+		return append(path, pathErr.Path...), err
+	}
+	if !val.IsKnown() || val.IsNull() {
+		return path, err
+	}
+
+	ty := val.Type()
+	switch {
+	case want.IsListType() || want.IsSetType():
+		if !ty.IsTupleType() && !ty.IsListType() && !ty.IsSetType() {
+			return path, err
+		}
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			if _, elemErr := convert.Convert(elem, want.ElementType()); elemErr != nil {
+				return conversionError(elem, want.ElementType(), append(path, cty.IndexStep{Key: key}), elemErr)
+			}
+		}
+	case want.IsMapType():
+		if !ty.IsObjectType() && !ty.IsMapType() {
+			return path, err
+		}
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			if _, elemErr := convert.Convert(elem, want.ElementType()); elemErr != nil {
+				return conversionError(elem, want.ElementType(), append(path, cty.IndexStep{Key: key}), elemErr)
+			}
+		}
+	case want.IsObjectType():
+		if !ty.IsObjectType() && !ty.IsMapType() {
+			return path, err
+		}
+		for name, attrType := range want.AttributeTypes() {
+			var attr cty.Value
+			switch {
+			case ty.IsObjectType() && ty.HasAttribute(name):
+				attr = val.GetAttr(name)
+			case ty.IsMapType() && val.HasIndex(cty.StringVal(name)).True():
+				attr = val.Index(cty.StringVal(name))
+			case want.AttributeOptional(name):
+				continue
+			default:
+				return path, fmt.Errorf("attribute %q is required", name)
+			}
+			if _, attrErr := convert.Convert(attr, attrType); attrErr != nil {
+				return conversionError(attr, attrType, append(path, cty.GetAttrStep{Name: name}), attrErr)
+			}
+		}
+	}
+	return path, err
+}
+
+// formatPath renders a cty path the way it would be written in HCL, e.g.
+// [0].username.
+func formatPath(path cty.Path) string {
+	var b strings.Builder
+	for _, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			b.WriteString("." + s.Name)
+		case cty.IndexStep:
+			switch s.Key.Type() {
+			case cty.Number:
+				b.WriteString("[" + s.Key.AsBigFloat().Text('f', -1) + "]")
+			case cty.String:
+				b.WriteString(fmt.Sprintf("[%q]", s.Key.AsString()))
+			default:
+				b.WriteString("[?]")
+			}
+		}
+	}
+	return b.String()
+}
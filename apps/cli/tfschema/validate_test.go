@@ -0,0 +1,277 @@
+package tfschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testVariables = `
+variable "project_name" {
+  type = string
+}
+
+variable "region" {
+  type    = string
+  default = "eu-west-1"
+}
+
+variable "provision_vpc" {
+  type    = bool
+  default = true
+}
+
+variable "vpc_cidr" {
+  type    = string
+  default = "10.0.0.0/16"
+}
+
+variable "redis_allowed_cidr_blocks" {
+  type    = list(string)
+  default = []
+}
+
+variable "node_min_size" {
+  type    = number
+  default = 1
+}
+
+variable "node_desired_size" {
+  type    = number
+  default = 2
+}
+
+variable "node_max_size" {
+  type    = number
+  default = 3
+}
+
+variable "rds_scaling_config" {
+  type = object({
+    min_capacity = number
+    max_capacity = optional(number, 4)
+  })
+  default = { min_capacity = 1, max_capacity = 4 }
+}
+
+variable "eks_cluster_admins" {
+  type = list(object({
+    username = string
+    arn      = string
+  }))
+  default = []
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+`
+
+func TestValidate(t *testing.T) {
+	schema, err := Parse([]byte(testVariables), "variables.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tfvars string
+		// want lists the problems as "path: message", warnings prefixed
+		// with "warning: ".
+		want []string
+	}{
+		{
+			name:   "valid",
+			tfvars: `project_name = "shop"`,
+		},
+		{
+			name:   "required variable missing",
+			tfvars: `region = "eu-central-1"`,
+			want:   []string{"project_name: required variable is not set"},
+		},
+		{
+			name:   "required variable empty",
+			tfvars: `project_name = "  "`,
+			want:   []string{"project_name: required variable is empty"},
+		},
+		{
+			name: "undeclared variable",
+			tfvars: `project_name = "shop"
+unknown = 1`,
+			want: []string{"warning: unknown: variable is not declared in variables.tf and will be ignored"},
+		},
+		{
+			name: "numbers and bools convert from strings",
+			tfvars: `project_name  = "shop"
+node_min_size = "1"
+provision_vpc = "true"`,
+		},
+		{
+			name: "wrong type",
+			tfvars: `project_name  = "shop"
+node_min_size = "one"`,
+			want: []string{"node_min_size: a number is required"},
+		},
+		{
+			name: "wrong type in a nested attribute",
+			tfvars: `project_name       = "shop"
+eks_cluster_admins = [{ username = "a", arn = ["x"] }]`,
+			want: []string{"eks_cluster_admins[0].arn: string required, but have tuple"},
+		},
+		{
+			name: "missing object attribute",
+			tfvars: `project_name       = "shop"
+eks_cluster_admins = [{ username = "a" }]`,
+			want: []string{`eks_cluster_admins[0]: attribute "arn" is required`},
+		},
+		{
+			name: "wrong type in a map",
+			tfvars: `project_name = "shop"
+tags         = { team = "shop", cost = ["x"] }`,
+			want: []string{`tags["cost"]: string required, but have tuple`},
+		},
+		{
+			name: "optional attribute default",
+			tfvars: `project_name       = "shop"
+rds_scaling_config = { min_capacity = 2 }`,
+		},
+		{
+			name: "invalid CIDR",
+			tfvars: `project_name = "shop"
+vpc_cidr     = "10.0.0.0/33"`,
+			want: []string{`vpc_cidr: "10.0.0.0/33" is not a valid CIDR block`},
+		},
+		{
+			name: "CIDR with host bits",
+			tfvars: `project_name = "shop"
+vpc_cidr     = "10.0.0.1/16"`,
+			want: []string{`vpc_cidr: "10.0.0.1/16" has host bits set, did you mean 10.0.0.0/16?`},
+		},
+		{
+			name: "VPC CIDR too large",
+			tfvars: `project_name = "shop"
+vpc_cidr     = "10.0.0.0/8"`,
+			want: []string{"vpc_cidr: 10.0.0.0/8 must be an IPv4 block between /16 and /28"},
+		},
+		{
+			name: "VPC CIDR not checked without provision_vpc",
+			tfvars: `project_name  = "shop"
+provision_vpc = false
+vpc_cidr      = "10.0.0.0/8"`,
+		},
+		{
+			name: "VPC CIDR required with provision_vpc",
+			tfvars: `project_name = "shop"
+vpc_cidr     = ""`,
+			want: []string{"vpc_cidr: required when provision_vpc is true"},
+		},
+		{
+			name: "overlapping CIDR list entries",
+			tfvars: `project_name              = "shop"
+redis_allowed_cidr_blocks = ["10.0.0.0/16", "192.168.0.0/24", "10.0.1.0/24"]`,
+			want: []string{"redis_allowed_cidr_blocks[2]: 10.0.1.0/24 overlaps redis_allowed_cidr_blocks[0] (10.0.0.0/16)"},
+		},
+		{
+			name: "sizes in order",
+			tfvars: `project_name      = "shop"
+node_min_size     = 2
+node_desired_size = 2
+node_max_size     = 2`,
+		},
+		{
+			name: "max below min",
+			tfvars: `project_name      = "shop"
+node_min_size     = 3
+node_desired_size = 3
+node_max_size     = 2`,
+			want: []string{
+				"node_max_size: 2 is less than node_min_size (3)",
+				"node_desired_size: 3 is greater than node_max_size (2)",
+			},
+		},
+		{
+			name: "desired outside min and max",
+			tfvars: `project_name      = "shop"
+node_desired_size = 0`,
+			want: []string{"node_desired_size: 0 is less than node_min_size (1)"},
+		},
+		{
+			name: "negative min",
+			tfvars: `project_name      = "shop"
+node_min_size     = -1`,
+			want: []string{"node_min_size: must not be negative"},
+		},
+		{
+			name: "capacity object out of order",
+			tfvars: `project_name       = "shop"
+rds_scaling_config = { min_capacity = 8, max_capacity = 2 }`,
+			want: []string{"rds_scaling_config.max_capacity: 2 is less than min_capacity (8)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := ParseValues([]byte(tt.tfvars), "test.tfvars")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, p := range schema.Validate(values) {
+				s := p.String()
+				if p.Warning {
+					s = "warning: " + s
+				}
+				got = append(got, s)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors([]Problem{{Path: "x", Warning: true}}) {
+		t.Error("HasErrors() = true for warnings only")
+	}
+	if !HasErrors([]Problem{{Path: "x", Warning: true}, {Path: "y"}}) {
+		t.Error("HasErrors() = false with an error")
+	}
+}
+
+func TestProblemRange(t *testing.T) {
+	schema, err := Parse([]byte(testVariables), "variables.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := ParseValues([]byte("project_name = \"shop\"\n\nvpc_cidr = \"bad\"\n"), "test.tfvars")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems := schema.Validate(values)
+	if len(problems) != 1 || problems[0].Range.Start.Line != 3 {
+		t.Errorf("Validate() = %+v, want one problem on line 3", problems)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	schema, err := Template()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Variables) == 0 {
+		t.Fatal("Template() declares no variables")
+	}
+	if _, ok := schema.Lookup("vpc_cidr"); !ok {
+		t.Error("Template() does not declare vpc_cidr")
+	}
+}
+
+func TestParseDuplicateVariable(t *testing.T) {
+	src := `variable "a" {}
+variable "a" {}`
+	if _, err := Parse([]byte(src), "variables.tf"); err == nil {
+		t.Error("Parse() accepted a variable declared twice")
+	}
+}
@@ -0,0 +1,47 @@
+package tfschema
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Value is a variable assignment read from a tfvars file.
+type Value struct {
+	Value cty.Value
+	Range hcl.Range
+}
+
+// ParseValues reads the assignments of a tfvars file.
+func ParseValues(src []byte, filename string) (map[string]Value, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	values := make(map[string]Value, len(attrs))
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		values[name] = Value{Value: val, Range: attr.NameRange}
+	}
+	return values, nil
+}
+
+// sortedNames returns the keys of values in alphabetical order.
+func sortedNames(values map[string]Value) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
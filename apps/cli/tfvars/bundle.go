@@ -0,0 +1,94 @@
+package tfvars
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+)
+
+// Export formats.
+const (
+	FormatTFVars = "tfvars"
+	FormatYAML   = "yaml"
+	FormatZip    = "zip"
+)
+
+// Names of the exported files.
+const (
+	VariablesFile     = "terraform.tfvars"
+	VariablesYAMLFile = "terraform.tfvars.yaml"
+	BackendFile       = "backend.tfvars"
+)
+
+// zipTime is stamped on every archive entry so archives only change when
+// their contents do. It is the earliest time the zip format can represent.
+var zipTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// File is a rendered file of an export bundle.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Bundle renders the files for a configuration in the given format. Every
+// format includes backend.tfvars; the zip format packs the tfvars files into
+// a single `<project>-<environment>.zip`. The output only depends on the
+// configuration, so exporting the same configuration twice gives identical
+// bytes.
+func Bundle(config types.Configuration, format string) ([]File, error) {
+	vars, err := FromConfiguration(config)
+	if err != nil {
+		return nil, err
+	}
+	backend := File{Name: BackendFile, Data: Backend(config).Bytes()}
+
+	switch format {
+	case FormatTFVars:
+		return []File{{Name: VariablesFile, Data: vars.Bytes()}, backend}, nil
+	case FormatYAML:
+		data, err := vars.YAML()
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Name: VariablesYAMLFile, Data: data}, backend}, nil
+	case FormatZip:
+		data, err := Zip([]File{{Name: VariablesFile, Data: vars.Bytes()}, backend})
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("%s-%s.zip", config.ProjectName, config.EnvironmentStage)
+		return []File{{Name: name, Data: data}}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected %s, %s or %s)", format, FormatTFVars, FormatYAML, FormatZip)
+	}
+}
+
+// Zip packs files into an archive in the given order, with fixed timestamps
+// and permissions so the result is reproducible.
+func Zip(files []File) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		header := &zip.FileHeader{
+			Name:     f.Name,
+			Method:   zip.Deflate,
+			Modified: zipTime,
+		}
+		header.SetMode(0644)
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package tfvars
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bobikenobi12/bb-thesis-2026/apps/cli/types"
+	"gopkg.in/yaml.v3"
+)
+
+// FromConfiguration builds the contents of terraform.tfvars for the
+// infrastructure template, starting from the template defaults and
+// overriding them with the values stored in the configuration.
+func FromConfiguration(config types.Configuration) (*Vars, error) {
+	v := Defaults()
+
+	v.Set("environment", config.EnvironmentStage)
+	v.Set("region", config.AwsRegion)
+	v.Set("project_name", config.ProjectName)
+	v.Set("aws_account_id", config.AwsAccountID)
+
+	if config.CreateVpc != nil {
+		v.Set("provision_vpc", *config.CreateVpc)
+	}
+	if config.VpcCidr != nil {
+		v.Set("vpc_cidr", *config.VpcCidr)
+	}
+
+	if config.EksClusterAdmins != nil {
+		admins, err := parseClusterAdmins(*config.EksClusterAdmins)
+		if err != nil {
+			return nil, fmt.Errorf("eks_cluster_admins: %w", err)
+		}
+		v.Set("eks_cluster_admins", admins)
+	}
+
+	if config.DbMinCapacity != nil || config.DbMaxCapacity != nil {
+		scaling, _ := v.Get("rds_scaling_config")
+		s := scaling.(map[string]any)
+		if config.DbMinCapacity != nil {
+			s["min_capacity"] = *config.DbMinCapacity
+		}
+		if config.DbMaxCapacity != nil {
+			s["max_capacity"] = *config.DbMaxCapacity
+		}
+	}
+
+	if config.EnableCloudfrontWaf != nil {
+		v.Set("cloudfront_waf_enabled", *config.EnableCloudfrontWaf)
+	}
+
+	if config.EnableRedis != nil {
+		v.Set("create_elasticache_redis", *config.EnableRedis)
+	}
+	if config.RedisAllowedCidrBlocks != nil {
+		blocks, err := parseStringList(*config.RedisAllowedCidrBlocks)
+		if err != nil {
+			return nil, fmt.Errorf("redis_allowed_cidr_blocks: %w", err)
+		}
+		v.Set("redis_allowed_cidr_blocks", blocks)
+	}
+
+	if config.EnableDns != nil {
+		v.Set("acm_certificate_enable", *config.EnableDns)
+	}
+	if config.DnsHostedZone != nil {
+		v.Set("dns_hosted_zone", *config.DnsHostedZone)
+	}
+	if config.DnsDomainName != nil {
+		v.Set("dns_main_domain", *config.DnsDomainName)
+	}
+
+	if config.EnableKarpenter != nil {
+		v.Set("enable_karpenter", *config.EnableKarpenter)
+	}
+
+	return v, nil
+}
+
+// Backend builds the contents of backend.tfvars. The state bucket and key are
+// derived from the project, environment and region so every environment gets
+// its own state.
+func Backend(config types.Configuration) *Vars {
+	prefix := StatePrefix(config)
+
+	v := NewVars()
+	v.Set("encrypt", true)
+	v.Set("bucket", prefix+"-idp-state")
+	v.Set("region", config.AwsRegion)
+	v.Set("key", prefix+"-terraform.tfstate")
+	return v
+}
+
+// StatePrefix returns the `<project>-<environment>-<region>` prefix used to
+// name the state bucket and key.
+func StatePrefix(config types.Configuration) string {
+	return strings.Join([]string{config.ProjectName, config.EnvironmentStage, config.AwsRegion}, "-")
+}
+
+// parseClusterAdmins accepts the admins stored by the web portal, either as a
+// YAML/JSON list of usernames or as a list of {username, path} objects.
+func parseClusterAdmins(raw string) ([]any, error) {
+	var items []any
+	if err := yaml.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, err
+	}
+
+	admins := make([]any, 0, len(items))
+	for _, item := range items {
+		switch a := item.(type) {
+		case string:
+			admins = append(admins, map[string]any{"username": a})
+		case map[string]any:
+			if _, ok := a["username"]; !ok {
+				return nil, fmt.Errorf("entry %v is missing a username", a)
+			}
+			admins = append(admins, a)
+		default:
+			return nil, fmt.Errorf("unexpected entry %v", item)
+		}
+	}
+	return admins, nil
+}
+
+// parseStringList accepts a YAML/JSON list, or a single comma separated value.
+func parseStringList(raw string) ([]any, error) {
+	var items []any
+	if err := yaml.Unmarshal([]byte(raw), &items); err == nil {
+		return items, nil
+	}
+
+	items = []any{}
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			items = append(items, s)
+		}
+	}
+	return items, nil
+}
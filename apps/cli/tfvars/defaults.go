@@ -0,0 +1,172 @@
+package tfvars
+
+// Defaults returns the baseline variables from the template's
+// variable-template/terraform.tfvars, with the account specific values
+// (VPC, subnets, users) cleared. Variables without a default in
+// variables.tf must always be present here.
+func Defaults() *Vars {
+	v := NewVars()
+
+	// General
+	v.Set("environment", "")
+	v.Set("region", "")
+	v.Set("project_name", "")
+	v.Set("aws_account_id", "")
+	v.Set("rds_iam_irsa", true)
+	v.Set("allow_long_names", true)
+
+	// Networking
+	v.Set("provision_vpc", true)
+	v.Set("vpc_cidr", "10.56.0.0/16")
+	v.Set("vpc_id", "")
+	v.Set("vpc_private_subnet_ids", []any{})
+	v.Set("vpc_public_subnet_ids", []any{})
+	v.Set("vpc_single_nat_gateway", false)
+
+	// EKS
+	v.Set("provision_eks", true)
+	v.Set("eks_cluster_version", "1.34")
+	v.Set("eks_instance_types", []any{"m5a.large"})
+	v.Set("addons_versions", map[string]any{
+		"coredns":    "v1.12.3-eksbuild.1",
+		"kube_proxy": "v1.34.0-eksbuild.2",
+		"vpc_cni":    "v1.20.4-eksbuild.1",
+		"ebs_csi":    "v1.51.1-eksbuild.1",
+	})
+	v.Set("eks_ng_min_size", 2)
+	v.Set("eks_ng_desired_size", 2)
+	v.Set("eks_ng_max_size", 4)
+	v.Set("eks_ng_capacity_type", "SPOT")
+	v.Set("eks_cluster_admins", []any{})
+	v.Set("eks_access_entries", map[string]any{})
+	v.Set("eks_kms_key_users", []any{})
+
+	// RDS
+	v.Set("create_rds", true)
+	v.Set("rds_extra_credentials", map[string]any{
+		"username": "demouser",
+		"database": "demodb",
+	})
+	v.Set("rds_scaling_config", map[string]any{
+		"min_capacity": 0.5,
+		"max_capacity": 2,
+	})
+	v.Set("rds_config", map[string]any{
+		"engine":         "aurora-postgresql",
+		"engine_version": "14.15",
+		"engine_mode":    "provisioned",
+		"cluster_family": "aurora-postgresql14",
+		"cluster_size":   1,
+		"db_port":        5432,
+		"db_name":        "",
+	})
+	v.Set("rds_iam_auth_enabled", false)
+	v.Set("rds_logs_exports", []any{"postgresql"})
+	v.Set("rds_default_username", "postgres")
+	v.Set("rds_allowed_cidr_blocks", []any{})
+	v.Set("rds_backup_retention_period", 5)
+	v.Set("rds_instance_type", "db.serverless")
+	v.Set("rds_cluster_parameters", []any{})
+
+	// SQS / SNS
+	v.Set("sqs_username", "")
+	v.Set("sqs_iam_role_name", "")
+	v.Set("provision_sqs", false)
+	v.Set("sqs_queues", map[string]any{})
+	v.Set("sns_topics", map[string]any{})
+
+	// WAF
+	v.Set("application_waf_enabled", false)
+	v.Set("cloudfront_waf_enabled", false)
+	v.Set("waf_sampled_requests_enabled", true)
+	v.Set("waf_webacl_cloudwatch_enabled", true)
+	v.Set("waf_logging_enabled", true)
+	v.Set("waf_log_retention_days", 365)
+	v.Set("waf_country_codes_match", []any{"CU", "IR", "SY", "KP", "RU"})
+	v.Set("custom_managed_waf_rule_groups", []any{})
+	v.Set("aws_managed_waf_rule_groups", []any{
+		wafRuleGroup("AWSManagedRulesAdminProtectionRuleSet", 2),
+		wafRuleGroup("AWSManagedRulesCommonRuleSet", 3),
+		wafRuleGroup("AWSManagedRulesKnownBadInputsRuleSet", 4),
+		wafRuleGroup("AWSManagedRulesLinuxRuleSet", 5),
+		wafRuleGroup("AWSManagedRulesSQLiRuleSet", 6),
+	})
+	v.Set("custom_waf_rules", []any{})
+
+	// ECR
+	v.Set("provision_ecr", false)
+	v.Set("ecr_repository_type", "private")
+	v.Set("ecr_repository_image_tag_mutability", "IMMUTABLE")
+	v.Set("ecr_repository_encryption_type", "AES256")
+	v.Set("ecr_repository_image_scan_on_push", false)
+	v.Set("ecr_repository_read_access_arns", []any{})
+	v.Set("ecr_repository_read_write_access_arns", []any{})
+	v.Set("ecr_manage_registry_scanning_configuration", false)
+	v.Set("ecr_registry_scan_type", "BASIC")
+	v.Set("ecr_registry_scan_rules", []any{})
+	v.Set("ecr_create_lifecycle_policy", false)
+	v.Set("ecr_names_map", map[string]any{})
+
+	// Elasticache Redis
+	v.Set("create_elasticache_redis", false)
+	v.Set("redis_cluster_size", 1)
+	v.Set("redis_cluster_mode_enabled", false)
+	v.Set("redis_instance_type", "cache.t3.medium")
+	v.Set("redis_engine_version", "7.0")
+	v.Set("redis_family", "redis7")
+	v.Set("redis_allowed_security_group_ids", []any{})
+	v.Set("redis_allowed_cidr_blocks", []any{})
+	v.Set("redis_cloudwatch_logs_enabled", true)
+	v.Set("redis_multi_az_enabled", false)
+	v.Set("redis_automatic_failover_enabled", false)
+
+	// Elasticache Valkey
+	v.Set("create_elasticache_valkey", false)
+	v.Set("valkey_snapshot_time", "03:00")
+	v.Set("valkey_engine_version", "7")
+	v.Set("valkey_data_storage_max", 4)
+	v.Set("valkey_ecpu_per_second_max", 2000)
+	v.Set("valkey_create_valkey_user_and_secret", true)
+
+	// ACM / DNS
+	v.Set("acm_certificate_enable", false)
+	v.Set("dns_hosted_zone", "")
+	v.Set("dns_main_domain", "")
+
+	// Karpenter
+	v.Set("enable_karpenter", true)
+	v.Set("ec2_spot_service_role", false)
+
+	// Custom secrets
+	v.Set("custom_secrets", []any{})
+
+	// DynamoDB
+	v.Set("ddb_create", false)
+	v.Set("ddb_table_configuration", []any{
+		map[string]any{
+			"table_name_suffix": "dynamodb-table",
+			"hash_key":          "Id",
+			"range_key":         "version",
+			"hash_key_type":     "S",
+			"range_key_type":    "N",
+		},
+	})
+	v.Set("ddb_global_create", false)
+	v.Set("ddb_global_table_configuration", []any{})
+
+	// S3
+	v.Set("s3_create", false)
+
+	v.Set("custom_terraform_vars", map[string]any{})
+
+	return v
+}
+
+func wafRuleGroup(name string, priority int) map[string]any {
+	return map[string]any{
+		"name":                    name,
+		"priority":                priority,
+		"action":                  "none",
+		"rules_override_to_count": []any{},
+	}
+}
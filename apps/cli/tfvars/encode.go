@@ -0,0 +1,172 @@
+package tfvars
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Vars is an ordered set of Terraform variable assignments. Order is kept so
+// rendered files group related variables the same way the template does.
+type Vars struct {
+	keys   []string
+	values map[string]any
+}
+
+func NewVars() *Vars {
+	return &Vars{values: map[string]any{}}
+}
+
+// Set assigns a value, keeping the original position if the key already exists.
+func (v *Vars) Set(name string, value any) {
+	if _, ok := v.values[name]; !ok {
+		v.keys = append(v.keys, name)
+	}
+	v.values[name] = value
+}
+
+func (v *Vars) Get(name string) (any, bool) {
+	value, ok := v.values[name]
+	return value, ok
+}
+
+func (v *Vars) Keys() []string {
+	return append([]string(nil), v.keys...)
+}
+
+// Bytes renders the variables in tfvars syntax, formatted the way
+// `terraform fmt` would lay them out.
+func (v *Vars) Bytes() []byte {
+	var buf bytes.Buffer
+	writeBody(&buf, v.keys, v.values, 0, false)
+	return buf.Bytes()
+}
+
+// writeBody writes one attribute per line, aligning the `=` of consecutive
+// single-line attributes.
+func writeBody(buf *bytes.Buffer, keys []string, values map[string]any, indent int, quoteKeys bool) {
+	pad := strings.Repeat("  ", indent)
+
+	names := make([]string, len(keys))
+	rendered := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k
+		if quoteKeys {
+			names[i] = strconv.Quote(k)
+		}
+		rendered[i] = encodeValue(values[k], indent)
+	}
+
+	for i := 0; i < len(keys); {
+		// Find the run of single-line values starting at i.
+		j := i
+		width := 0
+		for j < len(keys) && !strings.Contains(rendered[j], "\n") {
+			if len(names[j]) > width {
+				width = len(names[j])
+			}
+			j++
+		}
+		if j == i {
+			fmt.Fprintf(buf, "%s%s = %s\n", pad, names[i], rendered[i])
+			i++
+			continue
+		}
+		for ; i < j; i++ {
+			fmt.Fprintf(buf, "%s%-*s = %s\n", pad, width, names[i], rendered[i])
+		}
+	}
+}
+
+func encodeValue(value any, indent int) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case []string:
+		items := make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return encodeList(items, indent)
+	case []any:
+		return encodeList(v, indent)
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, m := range v {
+			items[i] = m
+		}
+		return encodeList(items, indent)
+	case map[string]any:
+		return encodeMap(v, indent)
+	case *Vars:
+		if len(v.keys) == 0 {
+			return "{}"
+		}
+		var buf bytes.Buffer
+		buf.WriteString("{\n")
+		writeBody(&buf, v.keys, v.values, indent+1, false)
+		buf.WriteString(strings.Repeat("  ", indent) + "}")
+		return buf.String()
+	default:
+		return quote(fmt.Sprint(v))
+	}
+}
+
+func encodeList(items []any, indent int) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	pad := strings.Repeat("  ", indent+1)
+	var buf bytes.Buffer
+	buf.WriteString("[\n")
+	for i, item := range items {
+		buf.WriteString(pad + encodeValue(item, indent+1))
+		if i < len(items)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(strings.Repeat("  ", indent) + "]")
+	return buf.String()
+}
+
+func encodeMap(m map[string]any, indent int) string {
+	if len(m) == 0 {
+		return "{}"
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	writeBody(&buf, keys, m, indent+1, true)
+	buf.WriteString(strings.Repeat("  ", indent) + "}")
+	return buf.String()
+}
+
+// quote produces an HCL string literal, escaping template sequences so values
+// are never interpolated by Terraform.
+func quote(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q, "${", "$${")
+	q = strings.ReplaceAll(q, "%{", "%%{")
+	return q
+}
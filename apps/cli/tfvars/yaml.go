@@ -0,0 +1,30 @@
+package tfvars
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML renders the variables as a YAML mapping in the same order as Bytes.
+func (v *Vars) YAML() ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range v.keys {
+		var value yaml.Node
+		if err := value.Encode(v.values[k]); err != nil {
+			return nil, err
+		}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, &value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
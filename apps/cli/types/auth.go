@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // ExchangeResponse defines the structure of the JSON response from the token exchange endpoint.
 type ExchangeResponse struct {
 	AccessToken   string `json:"access_token"`
@@ -7,3 +9,52 @@ type ExchangeResponse struct {
 	ProviderToken string `json:"provider_token,omitempty"`
 	UserEmail     string `json:"user_email"`
 }
+
+// APIToken is a long-lived token for CI, created with `grape token create`.
+// The token itself is only returned once, when it is created.
+type APIToken struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the start of the token, to tell tokens apart.
+	Prefix     string     `json:"token_prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TokenInfo describes the token of a request, as seen by the portal.
+type TokenInfo struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	// TokenType is "login" for tokens from `grape login` and "api" for API
+	// tokens, which also have a name and scopes.
+	TokenType string     `json:"token_type"`
+	TokenName string     `json:"token_name,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// KeySet is the JSON Web Key Set (RFC 7517) the portal signs login tokens
+// with, along with the issuer and audience those tokens carry. Keys is empty
+// when the portal signs with a shared secret.
+type KeySet struct {
+	Keys     []JSONWebKey `json:"keys"`
+	Issuer   string       `json:"issuer"`
+	Audience string       `json:"audience"`
+}
+
+// JSONWebKey is a public key of a KeySet. Only EC and RSA keys are used.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	// Crv, X and Y are set for EC keys.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	// N and E are set for RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
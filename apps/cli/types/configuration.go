@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type ConfigurationSummary struct {
 	ID string `json:"id"`
@@ -32,7 +35,9 @@ type Configuration struct {
 	EnableRedis             *bool     `json:"enable_redis"`
 	EnvironmentRepository   *string   `json:"environment_repository"`
 	EnvironmentStage        string    `json:"environment_stage"`
-	FullConfig              *string   `json:"full_config"`
+	// FullConfig is the jsonb document the portal's wizard saves. Older rows
+	// hold it as a JSON encoded string.
+	FullConfig              json.RawMessage `json:"full_config"`
 	GitopsAppTemplate       *string   `json:"gitops_app_template"`
 	GitopsAppToken          *string   `json:"gitops_app_token"`
 	GitopsArgocdToken       *string   `json:"gitops_argocd_token"`
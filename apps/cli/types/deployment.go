@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// Deployment statuses exchanged between the portal and the agent.
+// A job moves QUEUED -> PROCESSING -> SUCCESS/FAILED.
+const (
+	DeploymentQueued     = "QUEUED"
+	DeploymentProcessing = "PROCESSING"
+	DeploymentSuccess    = "SUCCESS"
+	DeploymentFailed     = "FAILED"
+)
+
+// Deployment is a job row from the `deployments` table as handed out to agents.
+type Deployment struct {
+	ID              string         `json:"id"`
+	ClusterID       *string        `json:"cluster_id"`
+	ConfigurationID *string        `json:"configuration_id"`
+	Name            string         `json:"name"`
+	Status          string         `json:"status"`
+	ConfigSnapshot  *Configuration `json:"config_snapshot"`
+	ErrorMessage    *string        `json:"error_message"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
@@ -1,67 +1,110 @@
 'use client'
 
 import { useSearchParams } from 'next/navigation'
-import { useEffect, useState } from 'react'
-import { CheckCircle, Loader2 } from 'lucide-react'
+import { FormEvent, useState } from 'react'
+import { CheckCircle, Loader2, XCircle } from 'lucide-react'
+
+type Result = 'approved' | 'denied' | null
 
 export default function CliLoginPage() {
   const searchParams = useSearchParams()
-  const deviceCode = searchParams.get('device_code')
+  const [userCode, setUserCode] = useState(searchParams.get('user_code') ?? '')
   const [error, setError] = useState('')
-  const [loading, setLoading] = useState(true)
+  const [loading, setLoading] = useState(false)
+  const [result, setResult] = useState<Result>(null)
+  const [verificationCode, setVerificationCode] = useState('')
 
-  useEffect(() => {
-    async function approveDevice() {
-      if (!deviceCode) {
-        setError('Device code is missing from the URL.')
-        setLoading(false)
-        return
-      }
+  async function submit(action: 'approve' | 'deny') {
+    setLoading(true)
+    setError('')
 
-      const response = await fetch('/api/auth/cli/generate', {
-        method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
-        body: JSON.stringify({ device_code: deviceCode }),
-      })
+    const response = await fetch('/api/auth/cli/generate', {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ user_code: userCode, action }),
+    })
 
-      if (!response.ok) {
-        const errorData = await response.json()
-        setError(errorData.error || 'Failed to approve device.')
-      }
-      setLoading(false)
+    if (!response.ok) {
+      const errorData = await response.json()
+      setError(errorData.error || 'Failed to approve device.')
+    } else {
+      const data = await response.json()
+      setVerificationCode(data.verification_code ?? '')
+      setResult(action === 'approve' ? 'approved' : 'denied')
     }
+    setLoading(false)
+  }
 
-    approveDevice()
-  }, [deviceCode])
+  function onSubmit(e: FormEvent) {
+    e.preventDefault()
+    submit('approve')
+  }
 
   return (
     <div className="flex flex-col items-center justify-center min-h-screen bg-black text-white">
       <div className="p-8 bg-gray-900 rounded-lg shadow-md text-center max-w-md w-full">
         <h1 className="text-2xl font-bold mb-4">CLI Authentication</h1>
-        {loading && (
-          <div className="flex flex-col items-center justify-center">
-            <Loader2 className="h-12 w-12 animate-spin text-primary mb-4" />
-            <p className="text-gray-400">
-              Approving login attempt...
+        {result === null && (
+          <form onSubmit={onSubmit} className="flex flex-col items-center">
+            <p className="text-gray-400 mb-4">
+              Enter the code shown by <code>grape login</code> and check that it
+              matches your terminal before approving.
             </p>
-          </div>
+            <input
+              value={userCode}
+              onChange={(e) => setUserCode(e.target.value.toUpperCase())}
+              placeholder="XXXX-XXXX"
+              autoComplete="off"
+              autoFocus
+              spellCheck={false}
+              maxLength={9}
+              className="w-full mb-4 px-4 py-3 rounded-md bg-black border border-gray-700 text-center text-2xl font-mono tracking-widest"
+            />
+            {error && <p className="text-red-500 mb-4">{error}</p>}
+            <div className="flex gap-3 w-full">
+              <button
+                type="button"
+                disabled={loading || !userCode}
+                onClick={() => submit('deny')}
+                className="flex-1 px-4 py-2 rounded-md border border-gray-700 text-gray-300 disabled:opacity-50"
+              >
+                Deny
+              </button>
+              <button
+                type="submit"
+                disabled={loading || !userCode}
+                className="flex-1 px-4 py-2 rounded-md bg-primary text-primary-foreground disabled:opacity-50 flex items-center justify-center"
+              >
+                {loading && <Loader2 className="h-4 w-4 animate-spin mr-2" />}
+                Approve
+              </button>
+            </div>
+          </form>
         )}
-        {!loading && !error && (
+        {result === 'approved' && (
           <div className="flex flex-col items-center justify-center">
             <CheckCircle className="h-12 w-12 text-green-500 mb-4" />
             <p className="text-lg text-gray-300 mb-2">
-              Authentication successful!
+              Login approved. Enter this code in your terminal:
+            </p>
+            <p className="my-4 text-5xl font-bold font-mono tracking-widest">
+              {verificationCode}
             </p>
             <p className="text-gray-400">
-              You can now close this window and return to your terminal.
+              Do not share it. You can close this window once the CLI is
+              signed in.
             </p>
           </div>
         )}
-        {error && (
+        {result === 'denied' && (
           <div className="flex flex-col items-center justify-center">
-            <p className="text-red-500">{error}</p>
+            <XCircle className="h-12 w-12 text-red-500 mb-4" />
+            <p className="text-lg text-gray-300 mb-2">Login denied</p>
+            <p className="text-gray-400">
+              The CLI was not signed in. You can close this window.
+            </p>
           </div>
         )}
       </div>
@@ -0,0 +1,62 @@
+import {
+	DEFAULT_POLL_INTERVAL,
+	DEVICE_CODE_TTL_SECONDS,
+	generateDeviceCode,
+	generateUserCode,
+} from "@/lib/cli/device";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// Unique constraint violation, i.e. the user code is already taken.
+const UNIQUE_VIOLATION = "23505";
+
+// POST is the device authorization endpoint (RFC 8628 §3.1): it starts a
+// `grape login` by issuing a device code for the CLI and a user code for the
+// browser.
+export async function POST(req: Request) {
+	const supabase = await createServiceRoleClient();
+	const expiresAt = new Date(Date.now() + DEVICE_CODE_TTL_SECONDS * 1000);
+
+	// Remove logins that were never completed.
+	await supabase
+		.from("cli_logins")
+		.delete()
+		.lt("expires_at", new Date().toISOString());
+
+	for (let attempt = 0; attempt < 3; attempt++) {
+		const deviceCode = generateDeviceCode();
+		const userCode = generateUserCode();
+
+		const { error } = await supabase.from("cli_logins").insert({
+			device_code: deviceCode,
+			user_code: userCode,
+			expires_at: expiresAt.toISOString(),
+			interval: DEFAULT_POLL_INTERVAL,
+		});
+		if (error?.code === UNIQUE_VIOLATION) {
+			continue;
+		}
+		if (error) {
+			console.error("Error creating CLI login:", error);
+			return NextResponse.json(
+				{ error: "Failed to start login" },
+				{ status: 500 }
+			);
+		}
+
+		const verificationUri = new URL("/cli/login", req.url).toString();
+		return NextResponse.json(
+			{
+				device_code: deviceCode,
+				user_code: userCode,
+				verification_uri: verificationUri,
+				verification_uri_complete: `${verificationUri}?user_code=${encodeURIComponent(userCode)}`,
+				expires_in: DEVICE_CODE_TTL_SECONDS,
+				interval: DEFAULT_POLL_INTERVAL,
+			},
+			{ headers: { "Cache-Control": "no-store" } }
+		);
+	}
+
+	return NextResponse.json({ error: "Failed to start login" }, { status: 500 });
+}
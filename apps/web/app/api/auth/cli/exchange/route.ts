@@ -1,44 +1,165 @@
+import {
+	DEFAULT_POLL_INTERVAL,
+	DEVICE_CODE_GRANT_TYPE,
+	MAX_VERIFICATION_ATTEMPTS,
+	SLOW_DOWN_INCREMENT,
+	deviceError,
+	normalizeVerificationCode,
+} from "@/lib/cli/device";
+import {
+	ACCESS_TOKEN_TTL_SECONDS,
+	cliKeys,
+	cliTokenAudience,
+	createCliSession,
+	signCliToken,
+} from "@/lib/cli/sessions";
 import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
-import * as jose from "jose";
 import { NextResponse } from "next/server";
 
+// POST is the token endpoint of the device flow (RFC 8628 §3.4). Until the
+// user approves the login it answers 400 with authorization_pending, or
+// slow_down when polled faster than the interval. After approval it asks for
+// the verification code shown in the browser, and cancels the login after
+// MAX_VERIFICATION_ATTEMPTS wrong ones.
 export async function POST(req: Request) {
-	const { device_code } = await req.json();
+	const body = await req.json().catch(() => ({}));
+	const { grant_type, device_code, verification_code } = body as {
+		grant_type?: string;
+		device_code?: string;
+		verification_code?: string;
+	};
 
+	if (grant_type !== DEVICE_CODE_GRANT_TYPE) {
+		return deviceError(
+			"unsupported_grant_type",
+			`grant_type must be ${DEVICE_CODE_GRANT_TYPE}`
+		);
+	}
 	if (!device_code) {
-		return new Response(JSON.stringify({ error: "Missing device_code" }), {
-			status: 400,
-			headers: { "Content-Type": "application/json" },
-		});
+		return deviceError("invalid_request", "Missing device_code");
 	}
 
 	const supabase = await createServiceRoleClient();
 
-	// 1. Find the approved login record
+	// 1. Find the login record
 	const { data: loginData, error: loginError } = await supabase
 		.from("cli_logins")
 		.select("*, profiles(email)")
 		.eq("device_code", device_code)
-		.single();
+		.maybeSingle();
+
+	if (loginError) {
+		console.error("Error loading CLI login:", loginError);
+		return NextResponse.json(
+			{ error: "Failed to load login" },
+			{ status: 500 }
+		);
+	}
+	if (!loginData) {
+		return deviceError("invalid_grant", "Unknown device_code");
+	}
+
+	const now = new Date();
+	if (loginData.expires_at && new Date(loginData.expires_at) < now) {
+		await supabase.from("cli_logins").delete().eq("device_code", device_code);
+		return deviceError("expired_token", "The login code expired");
+	}
+	if (loginData.denied_at) {
+		await supabase.from("cli_logins").delete().eq("device_code", device_code);
+		return deviceError("access_denied", "The login was denied");
+	}
 
 	// The profile_id being present signifies approval
-	if (loginError || !loginData || !loginData.profile_id) {
-		return new Response(
-			JSON.stringify({ error: "Authentication pending or not found" }),
-			{
-				status: 404,
-				headers: { "Content-Type": "application/json" },
-			}
+	if (!loginData.profile_id) {
+		const interval = loginData.interval ?? DEFAULT_POLL_INTERVAL;
+		const lastPolled = loginData.last_polled_at
+			? new Date(loginData.last_polled_at).getTime()
+			: 0;
+		const tooFast = now.getTime() - lastPolled < interval * 1000;
+
+		await supabase
+			.from("cli_logins")
+			.update({
+				last_polled_at: now.toISOString(),
+				interval: tooFast ? interval + SLOW_DOWN_INCREMENT : interval,
+			})
+			.eq("device_code", device_code);
+
+		if (tooFast) {
+			return deviceError(
+				"slow_down",
+				`Poll at most every ${interval + SLOW_DOWN_INCREMENT} seconds`
+			);
+		}
+		return deviceError(
+			"authorization_pending",
+			"The login has not been approved yet"
 		);
 	}
 
-	// Clean up the used record
+	// The browser showed a verification code on approval; the CLI must send
+	// it back before any tokens are issued.
+	if (verification_code === undefined || verification_code === "") {
+		return deviceError(
+			"verification_required",
+			"Enter the verification code shown in the browser"
+		);
+	}
+
+	// Count the attempt before checking it. The update only matches the count
+	// that was read, so parallel guesses cannot share one attempt.
+	const attempts = (loginData.verification_attempts ?? 0) + 1;
+	const claim = supabase
+		.from("cli_logins")
+		.update({ verification_attempts: attempts })
+		.eq("device_code", device_code);
+	const { data: claimed, error: claimError } = await (
+		loginData.verification_attempts === null
+			? claim.is("verification_attempts", null)
+			: claim.eq("verification_attempts", loginData.verification_attempts)
+	).select("device_code");
+
+	if (claimError) {
+		console.error("Error saving verification attempt:", claimError);
+		return NextResponse.json(
+			{ error: "Failed to check verification code" },
+			{ status: 500 }
+		);
+	}
+	if (!claimed?.length) {
+		return deviceError(
+			"invalid_request",
+			"Another verification attempt is in progress"
+		);
+	}
+
+	const verificationCode = normalizeVerificationCode(verification_code);
+	if (!verificationCode || verificationCode !== loginData.verification_code) {
+		const remaining = MAX_VERIFICATION_ATTEMPTS - attempts;
+		if (remaining <= 0) {
+			await supabase
+				.from("cli_logins")
+				.delete()
+				.eq("device_code", device_code);
+			return deviceError(
+				"too_many_attempts",
+				"Too many incorrect verification codes; the login was cancelled"
+			);
+		}
+		return deviceError(
+			"invalid_verification_code",
+			`Incorrect verification code, ${remaining} ${remaining === 1 ? "attempt" : "attempts"} left`,
+			400,
+			{ attempts_left: remaining }
+		);
+	}
+
+	// Clean up the used record, so the device code works only once
 	await supabase.from("cli_logins").delete().eq("device_code", device_code);
 
-	// 2. Ensure the JWT secret is set
-	const jwtSecret = process.env.CLI_JWT_SECRET;
-	if (!jwtSecret) {
-		console.error("CLI_JWT_SECRET is not set in environment variables.");
+	// 2. Ensure the signing keys are set
+	const keys = await cliKeys();
+	if (!keys) {
 		return new Response(
 			JSON.stringify({ error: "Internal server configuration error" }),
 			{
@@ -48,38 +169,36 @@ export async function POST(req: Request) {
 		);
 	}
 
-	// 3. Create a new custom JWT for the CLI
-	const secret = new TextEncoder().encode(jwtSecret);
-	const alg = "HS256";
+	// 3. Start a session and create the tokens for the CLI
+	let sessionId: string;
+	try {
+		sessionId = await createCliSession(loginData.profile_id);
+	} catch (error) {
+		console.error("Error creating CLI session:", error);
+		return NextResponse.json(
+			{ error: "Failed to start session" },
+			{ status: 500 }
+		);
+	}
 
-	const accessToken = await new jose.SignJWT({
-		sub: loginData.profile_id,
-		email: loginData.profiles?.email,
-		type: "access",
-	})
-		.setProtectedHeader({ alg })
-		.setIssuedAt()
-		.setIssuer("urn:example:issuer")
-		.setAudience("urn:example:audience")
-		.setExpirationTime("1h")
-		.sign(secret);
-
-	const refreshToken = await new jose.SignJWT({
+	const claims = {
 		sub: loginData.profile_id,
 		email: loginData.profiles?.email,
-		type: "refresh",
-	})
-		.setProtectedHeader({ alg })
-		.setIssuedAt()
-		.setIssuer("urn:example:issuer")
-		.setAudience("urn:example:audience")
-		.setExpirationTime("90d")
-		.sign(secret);
-
-	return NextResponse.json({
-		access_token: accessToken,
-		refresh_token: refreshToken,
-		provider_token: loginData.verification_code,
-		user_email: loginData.profiles?.email,
-	});
+		sid: sessionId,
+	};
+	const audience = cliTokenAudience(req);
+	const accessToken = await signCliToken("access", claims, keys, audience);
+	const refreshToken = await signCliToken("refresh", claims, keys, audience);
+
+	return NextResponse.json(
+		{
+			access_token: accessToken,
+			token_type: "Bearer",
+			expires_in: ACCESS_TOKEN_TTL_SECONDS,
+			refresh_token: refreshToken,
+			provider_token: loginData.provider_token,
+			user_email: loginData.profiles?.email,
+		},
+		{ headers: { "Cache-Control": "no-store" } }
+	);
 }
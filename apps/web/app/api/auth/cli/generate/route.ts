@@ -1,7 +1,14 @@
+import {
+	generateVerificationCode,
+	normalizeUserCode,
+} from "@/lib/cli/device";
 import { createClient } from "@/lib/supabase/server";
 import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
 import { NextResponse } from "next/server";
 
+// POST approves or denies a pending `grape login` for the signed-in user,
+// identified by the user code shown in the terminal. Approving answers with
+// the verification code the user then types into the CLI.
 export async function POST(req: Request) {
 	const supabase = await createClient();
 	const {
@@ -9,38 +16,84 @@ export async function POST(req: Request) {
 	} = await supabase.auth.getSession();
 
 	if (!session) {
-		return new Response(JSON.stringify({ error: "Unauthorized" }), {
-			status: 401,
-			headers: { "Content-Type": "application/json" },
-		});
+		return NextResponse.json({ error: "Unauthorized" }, { status: 401 });
 	}
 
-	const { device_code } = await req.json();
-	if (!device_code) {
-		return new Response(JSON.stringify({ error: "Missing device_code" }), {
-			status: 400,
-			headers: { "Content-Type": "application/json" },
-		});
+	const { user_code, action = "approve" } = await req.json();
+	const userCode = normalizeUserCode(user_code);
+	if (!userCode) {
+		return NextResponse.json(
+			{ error: "Enter the code shown in your terminal, like WDJB-MJHT." },
+			{ status: 400 }
+		);
+	}
+	if (action !== "approve" && action !== "deny") {
+		return NextResponse.json(
+			{ error: 'action must be "approve" or "deny"' },
+			{ status: 400 }
+		);
 	}
 
 	const supabaseServiceRole = await createServiceRoleClient();
 
-	const { error } = await supabaseServiceRole.from("cli_logins").upsert({
-		device_code,
-		profile_id: session.user.id,
-		verification_code: session.provider_token, // Temporarily store provider token here
-	});
+	const { data: login, error: loadError } = await supabaseServiceRole
+		.from("cli_logins")
+		.select("device_code, expires_at, profile_id, denied_at")
+		.eq("user_code", userCode)
+		.maybeSingle();
+
+	if (loadError) {
+		console.error("Error loading CLI login attempt:", loadError);
+		return NextResponse.json(
+			{ error: "Failed to load login attempt" },
+			{ status: 500 }
+		);
+	}
+	if (
+		!login ||
+		(login.expires_at && new Date(login.expires_at) < new Date())
+	) {
+		return NextResponse.json(
+			{ error: "This code is invalid or has expired. Run `grape login` again." },
+			{ status: 404 }
+		);
+	}
+	if (login.profile_id || login.denied_at) {
+		return NextResponse.json(
+			{ error: "This code has already been used." },
+			{ status: 409 }
+		);
+	}
+
+	const verificationCode =
+		action === "approve" ? generateVerificationCode() : null;
+	const update =
+		action === "approve"
+			? {
+					profile_id: session.user.id,
+					provider_token: session.provider_token,
+					verification_code: verificationCode,
+					verification_attempts: 0,
+				}
+			: { denied_at: new Date().toISOString() };
+
+	const { error } = await supabaseServiceRole
+		.from("cli_logins")
+		.update(update)
+		.eq("device_code", login.device_code)
+		.is("profile_id", null)
+		.is("denied_at", null);
 
 	if (error) {
 		console.error("Error saving CLI login attempt:", error);
-		return new Response(
-			JSON.stringify({ error: "Failed to save login attempt" }),
-			{
-				status: 500,
-				headers: { "Content-Type": "application/json" },
-			}
+		return NextResponse.json(
+			{ error: "Failed to save login attempt" },
+			{ status: 500 }
 		);
 	}
 
-	return NextResponse.json({ success: true });
+	return NextResponse.json({
+		success: true,
+		verification_code: verificationCode,
+	});
 }
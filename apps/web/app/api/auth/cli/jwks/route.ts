@@ -0,0 +1,26 @@
+import { cliKeys, cliTokenAudience } from "@/lib/cli/sessions";
+import { NextResponse } from "next/server";
+
+// GET publishes the public key CLI tokens are signed with (RFC 7517), so the
+// CLI can verify them locally. The issuer and audience tell the CLI which
+// portal a token must come from. keys is empty while tokens are still signed
+// with the shared CLI_JWT_SECRET.
+export async function GET(req: Request) {
+	const keys = await cliKeys();
+	if (!keys) {
+		return NextResponse.json(
+			{ error: "Internal server configuration error" },
+			{ status: 500 }
+		);
+	}
+
+	const { issuer, audience } = cliTokenAudience(req);
+	return NextResponse.json(
+		{
+			keys: keys.publicJwk ? [keys.publicJwk] : [],
+			issuer,
+			audience,
+		},
+		{ headers: { "Cache-Control": "public, max-age=3600" } }
+	);
+}
@@ -0,0 +1,73 @@
+import {
+	ACCESS_TOKEN_TTL_SECONDS,
+	cliKeys,
+	cliTokenAudience,
+	isSessionActive,
+	signCliToken,
+	verifyCliJwt,
+} from "@/lib/cli/sessions";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// POST issues a new access token for a refresh token whose session has not
+// been revoked.
+export async function POST(req: Request) {
+	const { refresh_token } = await req.json().catch(() => ({}));
+	if (typeof refresh_token !== "string" || !refresh_token) {
+		return NextResponse.json(
+			{ error: "Missing refresh_token" },
+			{ status: 400 }
+		);
+	}
+
+	const keys = await cliKeys();
+	if (!keys) {
+		return NextResponse.json(
+			{ error: "Internal server configuration error" },
+			{ status: 500 }
+		);
+	}
+
+	let payload;
+	try {
+		payload = await verifyCliJwt(
+			refresh_token,
+			"refresh",
+			keys,
+			cliTokenAudience(req)
+		);
+	} catch {
+		return NextResponse.json(
+			{ error: "Unauthorized: Invalid refresh token" },
+			{ status: 401 }
+		);
+	}
+	if (!(await isSessionActive(payload.sid, payload.sub))) {
+		return NextResponse.json(
+			{ error: "Unauthorized: Session revoked" },
+			{ status: 401 }
+		);
+	}
+
+	const sid = payload.sid as string;
+	const supabase = await createServiceRoleClient();
+	await supabase
+		.from("cli_sessions")
+		.update({ refreshed_at: new Date().toISOString() })
+		.eq("id", sid);
+
+	const accessToken = await signCliToken(
+		"access",
+		{ sub: payload.sub!, email: payload.email as string | undefined, sid },
+		keys,
+		cliTokenAudience(req)
+	);
+	return NextResponse.json(
+		{
+			access_token: accessToken,
+			token_type: "Bearer",
+			expires_in: ACCESS_TOKEN_TTL_SECONDS,
+		},
+		{ headers: { "Cache-Control": "no-store" } }
+	);
+}
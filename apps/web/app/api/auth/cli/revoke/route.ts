@@ -0,0 +1,49 @@
+import { cliKeys, cliTokenAudience, verifyCliJwt } from "@/lib/cli/sessions";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// POST revokes the session of a refresh token, for `grape logout` (RFC 7009).
+// Like the RFC asks, invalid, expired and already revoked tokens are not
+// errors: the answer is 200 either way, so it reveals nothing about them.
+export async function POST(req: Request) {
+	const { token } = await req.json().catch(() => ({}));
+	if (typeof token !== "string" || !token) {
+		return NextResponse.json(
+			{ error: "invalid_request", error_description: "Missing token" },
+			{ status: 400 }
+		);
+	}
+
+	const keys = await cliKeys();
+	if (!keys) {
+		return NextResponse.json(
+			{ error: "Internal server configuration error" },
+			{ status: 500 }
+		);
+	}
+
+	let payload;
+	try {
+		payload = await verifyCliJwt(token, "refresh", keys, cliTokenAudience(req));
+	} catch {
+		return NextResponse.json({}, { status: 200 });
+	}
+
+	if (typeof payload.sid === "string" && payload.sub) {
+		const supabase = await createServiceRoleClient();
+		const { error } = await supabase
+			.from("cli_sessions")
+			.update({ revoked_at: new Date().toISOString() })
+			.eq("id", payload.sid)
+			.eq("profile_id", payload.sub)
+			.is("revoked_at", null);
+		if (error) {
+			console.error("Error revoking CLI session:", error);
+			return NextResponse.json(
+				{ error: "Failed to revoke session" },
+				{ status: 500 }
+			);
+		}
+	}
+	return NextResponse.json({}, { status: 200 });
+}
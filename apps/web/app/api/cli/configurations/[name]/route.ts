@@ -1,4 +1,8 @@
 import { verifyCliToken } from "@/lib/cli/auth";
+import {
+	activeDeploymentConflict,
+	pickWritableFields,
+} from "@/lib/cli/configurations";
 import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
 import { NextResponse } from "next/server";
 
@@ -54,3 +58,188 @@ export async function GET(
 
 	return NextResponse.json({ configuration: { ...configuration } });
 }
+
+// PATCH updates a configuration. When `expected_updated_at` is given the
+// update only applies if nobody changed the configuration since then;
+// otherwise 409 is returned.
+export async function PATCH(
+	req: Request,
+	{ params }: { params: Promise<{ name: string }> },
+) {
+	const { payload, error: authError } = await verifyCliToken(
+		req,
+		"configs:write",
+	);
+	if (authError) {
+		return authError;
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 },
+		);
+	}
+
+	const { name: projectName } = await params;
+
+	let body: { configuration?: Record<string, unknown>; expected_updated_at?: string };
+	try {
+		body = await req.json();
+	} catch {
+		return NextResponse.json({ error: "Invalid JSON body" }, { status: 400 });
+	}
+
+	const fields = pickWritableFields(body.configuration ?? {});
+	const supabase = await createServiceRoleClient();
+
+	const { data: current, error: lookupError } = await supabase
+		.from("configurations")
+		.select("id, updated_at")
+		.eq("user_id", userId)
+		.eq("project_name", projectName)
+		.maybeSingle();
+
+	if (lookupError) {
+		return NextResponse.json({ error: lookupError.message }, { status: 500 });
+	}
+	if (!current) {
+		return NextResponse.json(
+			{ error: "Configuration not found" },
+			{ status: 404 },
+		);
+	}
+
+	if (
+		body.expected_updated_at &&
+		(!current.updated_at ||
+			new Date(current.updated_at).getTime() !==
+				new Date(body.expected_updated_at).getTime())
+	) {
+		return NextResponse.json(
+			{
+				error: `Configuration was modified at ${current.updated_at}; fetch it again and retry`,
+			},
+			{ status: 409 },
+		);
+	}
+
+	if (fields.project_name && fields.project_name !== projectName) {
+		// A rename: the new name must be free, and running deployments must
+		// not lose track of their configuration.
+		const { data: existing, error: existingError } = await supabase
+			.from("configurations")
+			.select("id")
+			.eq("user_id", userId)
+			.eq("project_name", fields.project_name)
+			.maybeSingle();
+
+		if (existingError) {
+			return NextResponse.json(
+				{ error: existingError.message },
+				{ status: 500 },
+			);
+		}
+		if (existing) {
+			return NextResponse.json(
+				{ error: `Configuration ${fields.project_name} already exists` },
+				{ status: 409 },
+			);
+		}
+
+		const conflict = await activeDeploymentConflict(
+			supabase,
+			current.id,
+			projectName,
+		);
+		if (conflict) {
+			return conflict;
+		}
+	}
+
+	let update = supabase
+		.from("configurations")
+		.update({ ...fields, updated_at: new Date().toISOString() })
+		.eq("id", current.id);
+	if (current.updated_at) {
+		// Guards against a write between the lookup and the update.
+		update = update.eq("updated_at", current.updated_at);
+	}
+
+	const { data: configuration, error } = await update.select().maybeSingle();
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+	if (!configuration) {
+		return NextResponse.json(
+			{ error: "Configuration was modified concurrently; fetch it again and retry" },
+			{ status: 409 },
+		);
+	}
+
+	return NextResponse.json({ configuration });
+}
+
+// DELETE removes a configuration unless it has deployments in progress.
+export async function DELETE(
+	req: Request,
+	{ params }: { params: Promise<{ name: string }> },
+) {
+	const { payload, error: authError } = await verifyCliToken(
+		req,
+		"configs:write",
+	);
+	if (authError) {
+		return authError;
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 },
+		);
+	}
+
+	const { name: projectName } = await params;
+	const supabase = await createServiceRoleClient();
+
+	const { data: current, error: lookupError } = await supabase
+		.from("configurations")
+		.select("id")
+		.eq("user_id", userId)
+		.eq("project_name", projectName)
+		.maybeSingle();
+
+	if (lookupError) {
+		return NextResponse.json({ error: lookupError.message }, { status: 500 });
+	}
+	if (!current) {
+		return NextResponse.json(
+			{ error: "Configuration not found" },
+			{ status: 404 },
+		);
+	}
+
+	const conflict = await activeDeploymentConflict(
+		supabase,
+		current.id,
+		projectName,
+	);
+	if (conflict) {
+		return conflict;
+	}
+
+	const { error } = await supabase
+		.from("configurations")
+		.delete()
+		.eq("id", current.id);
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+
+	return NextResponse.json({ deleted: true });
+}
@@ -1,7 +1,24 @@
 import { verifyCliToken } from "@/lib/cli/auth";
+import { pickWritableFields } from "@/lib/cli/configurations";
 import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import type { TablesInsert } from "@/types/database.types";
 import { NextResponse } from "next/server";
 
+// Columns the list can be sorted by.
+const SORT_COLUMNS = [
+	"project_name",
+	"environment_stage",
+	"container_platform",
+	"created_at",
+	"updated_at",
+] as const;
+
+const MAX_LIMIT = 200;
+
+// GET lists the user's configurations. Optional query parameters:
+// stage, platform, updated_since (ISO 8601), search (project name,
+// description, stage or platform), sort and order, and limit and page
+// (1-based) for pagination. Without limit every match is returned.
 export async function GET(req: Request) {
 	const { payload, error: authError } = await verifyCliToken(req);
 	if (authError) {
@@ -18,12 +35,88 @@ export async function GET(req: Request) {
 		);
 	}
 
+	const params = new URL(req.url).searchParams;
+
+	const sort = params.get("sort") ?? "project_name";
+	if (!(SORT_COLUMNS as readonly string[]).includes(sort)) {
+		return NextResponse.json(
+			{ error: `Cannot sort by ${sort}` },
+			{ status: 400 }
+		);
+	}
+
+	const limit = params.has("limit") ? Number(params.get("limit")) : 0;
+	const page = params.has("page") ? Number(params.get("page")) : 1;
+	if (
+		!Number.isInteger(limit) ||
+		limit < 0 ||
+		limit > MAX_LIMIT ||
+		!Number.isInteger(page) ||
+		page < 1
+	) {
+		return NextResponse.json(
+			{ error: `limit must be between 1 and ${MAX_LIMIT} and page at least 1` },
+			{ status: 400 }
+		);
+	}
+
 	const supabase = await createServiceRoleClient();
-	const { data: configurations, error } = await supabase
+	let query = supabase
 		.from("configurations")
-		.select("*")
-		.eq("user_id", userId);
+		.select("*", { count: "exact" })
+		.eq("user_id", userId)
+		.order(sort as (typeof SORT_COLUMNS)[number], {
+			ascending: params.get("order") !== "desc",
+		})
+		.order("id");
+
+	const stage = params.get("stage");
+	if (stage) {
+		query = query.eq("environment_stage", stage);
+	}
+	const platform = params.get("platform");
+	if (platform) {
+		query = query.eq("container_platform", platform);
+	}
+	const updatedSince = params.get("updated_since");
+	if (updatedSince) {
+		if (Number.isNaN(Date.parse(updatedSince))) {
+			return NextResponse.json(
+				{ error: "updated_since must be an ISO 8601 timestamp" },
+				{ status: 400 }
+			);
+		}
+		query = query.gte("updated_at", updatedSince);
+	}
+	// Characters with a meaning in PostgREST filters are dropped.
+	const search = params.get("search")?.replace(/[%,()*\\]/g, "").trim();
+	if (search) {
+		const pattern = `%${search}%`;
+		query = query.or(
+			[
+				`project_name.ilike.${pattern}`,
+				`description.ilike.${pattern}`,
+				`environment_stage.ilike.${pattern}`,
+				`container_platform.ilike.${pattern}`,
+			].join(",")
+		);
+	}
+	if (limit > 0) {
+		const from = (page - 1) * limit;
+		query = query.range(from, from + limit - 1);
+	}
+
+	const { data: configurations, error, count } = await query;
 
+	if (error?.code === "PGRST103") {
+		// The page starts past the last match.
+		return NextResponse.json({
+			configurations: [],
+			total: count ?? 0,
+			page,
+			limit,
+		});
+	}
 	if (error) {
 		return new Response(JSON.stringify({ error: error.message }), {
 			status: 500,
@@ -32,5 +125,75 @@ export async function GET(req: Request) {
 
 	return NextResponse.json({
 		configurations,
+		total: count ?? configurations.length,
+		page,
+		limit,
 	});
 }
+
+export async function POST(req: Request) {
+	const { payload, error: authError } = await verifyCliToken(
+		req,
+		"configs:write"
+	);
+	if (authError) {
+		return authError;
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 }
+		);
+	}
+
+	let body: Record<string, unknown>;
+	try {
+		body = await req.json();
+	} catch {
+		return NextResponse.json({ error: "Invalid JSON body" }, { status: 400 });
+	}
+
+	const fields = pickWritableFields(body);
+	if (!fields.project_name) {
+		return NextResponse.json(
+			{ error: "project_name is required" },
+			{ status: 400 }
+		);
+	}
+
+	const supabase = await createServiceRoleClient();
+
+	const { data: existing, error: lookupError } = await supabase
+		.from("configurations")
+		.select("id")
+		.eq("user_id", userId)
+		.eq("project_name", fields.project_name)
+		.maybeSingle();
+
+	if (lookupError) {
+		return NextResponse.json({ error: lookupError.message }, { status: 500 });
+	}
+	if (existing) {
+		return NextResponse.json(
+			{ error: `Configuration ${fields.project_name} already exists` },
+			{ status: 409 }
+		);
+	}
+
+	const { data: configuration, error } = await supabase
+		.from("configurations")
+		.insert({
+			...fields,
+			user_id: userId,
+		} as TablesInsert<"configurations">)
+		.select()
+		.single();
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+
+	return NextResponse.json({ configuration }, { status: 201 });
+}
@@ -0,0 +1,3 @@
+// The agent addresses logs relative to its endpoint; they are stored by the
+// same handler `grape deploy` uses.
+export { POST } from "@/app/api/deployments/[id]/logs/route";
@@ -0,0 +1,105 @@
+import { verifyCliToken } from "@/lib/cli/auth";
+import {
+	CLAIMED_DEPLOYMENT_STATUSES,
+	finishedStatus,
+} from "@/lib/cli/deployments";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// PUT records the outcome of a deployment claimed by `grape agent run`:
+// { status: "SUCCESS" } or { status: "FAILED", error_message }.
+export async function PUT(
+	req: Request,
+	{ params }: { params: Promise<{ id: string }> }
+) {
+	try {
+		const { payload, error: authError } = await verifyCliToken(
+			req,
+			"configs:write"
+		);
+		if (authError) {
+			return authError;
+		}
+
+		const userId = payload.sub;
+		if (!userId) {
+			return NextResponse.json(
+				{ error: "Invalid token payload" },
+				{ status: 400 }
+			);
+		}
+
+		const { status, error_message } = await req.json();
+		const finished = finishedStatus(status);
+		if (!finished) {
+			return NextResponse.json(
+				{ error: "Status must be SUCCESS or FAILED." },
+				{ status: 400 }
+			);
+		}
+
+		const { id } = await params;
+		const supabase = await createServiceRoleClient();
+		const { data: deployment, error: fetchError } = await supabase
+			.from("deployments")
+			.select("status, started_at")
+			.eq("id", id)
+			.eq("profile_id", userId)
+			.maybeSingle();
+
+		if (fetchError) {
+			return NextResponse.json(
+				{ error: `Failed to fetch deployment: ${fetchError.message}` },
+				{ status: 500 }
+			);
+		}
+		if (!deployment) {
+			return NextResponse.json(
+				{ error: "Deployment not found" },
+				{ status: 404 }
+			);
+		}
+		if (
+			!(CLAIMED_DEPLOYMENT_STATUSES as readonly string[]).includes(
+				deployment.status
+			)
+		) {
+			return NextResponse.json(
+				{ error: `Deployment is ${deployment.status}, not running` },
+				{ status: 409 }
+			);
+		}
+
+		const completedAt = new Date();
+		const startedAt = new Date(
+			deployment.started_at ?? completedAt.toISOString()
+		);
+		const { data, error } = await supabase
+			.from("deployments")
+			.update({
+				status: finished,
+				error_message: error_message ?? null,
+				completed_at: completedAt.toISOString(),
+				duration_seconds:
+					(completedAt.getTime() - startedAt.getTime()) / 1000,
+			})
+			.eq("id", id)
+			.select()
+			.single();
+
+		if (error) {
+			return NextResponse.json(
+				{ error: `Failed to update deployment: ${error.message}` },
+				{ status: 500 }
+			);
+		}
+
+		return NextResponse.json({ deployment: data });
+	} catch (error) {
+		console.error("[v0] Error updating deployment:", error);
+		return NextResponse.json(
+			{ error: "Failed to update deployment" },
+			{ status: 500 }
+		);
+	}
+}
@@ -0,0 +1,83 @@
+import { verifyCliToken } from "@/lib/cli/auth";
+import { agentDeployment } from "@/lib/cli/deployments";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// How often a claim is retried when other agents take the job first.
+const CLAIM_ATTEMPTS = 3;
+
+// POST claims the oldest pending deployment of the token's user for
+// `grape agent run`, moving it to initializing. It answers 204 when there is
+// nothing to do.
+export async function POST(req: Request) {
+	try {
+		const { payload, error: authError } = await verifyCliToken(
+			req,
+			"configs:write"
+		);
+		if (authError) {
+			return authError;
+		}
+
+		const userId = payload.sub;
+		if (!userId) {
+			return NextResponse.json(
+				{ error: "Invalid token payload" },
+				{ status: 400 }
+			);
+		}
+
+		const supabase = await createServiceRoleClient();
+		for (let attempt = 0; attempt < CLAIM_ATTEMPTS; attempt++) {
+			const { data: next, error: fetchError } = await supabase
+				.from("deployments")
+				.select("id")
+				.eq("profile_id", userId)
+				.eq("status", "pending")
+				.order("created_at", { ascending: true })
+				.limit(1)
+				.maybeSingle();
+
+			if (fetchError) {
+				return NextResponse.json(
+					{ error: `Failed to fetch deployments: ${fetchError.message}` },
+					{ status: 500 }
+				);
+			}
+			if (!next) {
+				return new Response(null, { status: 204 });
+			}
+
+			// Only a pending row is updated, so of several agents polling at
+			// once exactly one claims it; the others try the next one.
+			const { data: claimed, error: claimError } = await supabase
+				.from("deployments")
+				.update({
+					status: "initializing",
+					started_at: new Date().toISOString(),
+				})
+				.eq("id", next.id)
+				.eq("status", "pending")
+				.select("*, configurations(*)")
+				.maybeSingle();
+
+			if (claimError) {
+				return NextResponse.json(
+					{ error: `Failed to claim deployment: ${claimError.message}` },
+					{ status: 500 }
+				);
+			}
+			if (claimed) {
+				return NextResponse.json({ deployment: agentDeployment(claimed) });
+			}
+		}
+
+		return new Response(null, { status: 204 });
+	} catch (error) {
+		console.error("[v0] Error claiming deployment:", error);
+		return NextResponse.json(
+			{ error: "Failed to claim deployment" },
+			{ status: 500 }
+		);
+	}
+}
@@ -0,0 +1,108 @@
+import { verifyCliToken } from "@/lib/cli/auth";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+export const dynamic = "force-dynamic";
+
+// Comment lines keep proxies from closing an idle stream.
+const HEARTBEAT_MS = 25_000;
+
+type ConfigurationRow = {
+	id?: string;
+	project_name?: string;
+	updated_at?: string | null;
+};
+
+// GET streams changes to the user's configurations as server-sent events,
+// for `grape config list --watch`. Each insert, update or delete is sent as a
+// "configuration" event; "ready" is sent once the subscription is active.
+export async function GET(req: Request) {
+	const { payload, error: authError } = await verifyCliToken(req);
+	if (authError) {
+		return authError;
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 }
+		);
+	}
+
+	const supabase = await createServiceRoleClient();
+	const encoder = new TextEncoder();
+	let cleanup = () => {};
+
+	const stream = new ReadableStream<Uint8Array>({
+		start(controller) {
+			let closed = false;
+			const write = (chunk: string) => {
+				if (!closed) {
+					controller.enqueue(encoder.encode(chunk));
+				}
+			};
+			const send = (event: string, data: unknown) =>
+				write(`event: ${event}\ndata: ${JSON.stringify(data)}\n\n`);
+
+			const channel = supabase
+				.channel(`cli-configurations-${userId}`)
+				.on(
+					"postgres_changes",
+					{
+						event: "*",
+						schema: "public",
+						table: "configurations",
+						filter: `user_id=eq.${userId}`,
+					},
+					(change) => {
+						const row = (
+							change.eventType === "DELETE" ? change.old : change.new
+						) as ConfigurationRow;
+						send("configuration", {
+							type: change.eventType,
+							id: row.id,
+							project_name: row.project_name,
+							updated_at: row.updated_at,
+						});
+					}
+				)
+				.subscribe((status) => {
+					if (status === "SUBSCRIBED") {
+						send("ready", {});
+					} else if (status === "CHANNEL_ERROR" || status === "TIMED_OUT") {
+						// The CLI reconnects, or falls back to polling.
+						cleanup();
+					}
+				});
+
+			const heartbeat = setInterval(() => write(": ping\n\n"), HEARTBEAT_MS);
+
+			cleanup = () => {
+				if (closed) {
+					return;
+				}
+				closed = true;
+				clearInterval(heartbeat);
+				supabase.removeChannel(channel);
+				try {
+					controller.close();
+				} catch {
+					// Already closed by the client.
+				}
+			};
+			req.signal.addEventListener("abort", () => cleanup());
+		},
+		cancel() {
+			cleanup();
+		},
+	});
+
+	return new Response(stream, {
+		headers: {
+			"Content-Type": "text/event-stream",
+			"Cache-Control": "no-cache, no-transform",
+			Connection: "keep-alive",
+		},
+	});
+}
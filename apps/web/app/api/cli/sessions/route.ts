@@ -0,0 +1,37 @@
+import { apiTokenForbidden, verifyCliToken } from "@/lib/cli/auth";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// DELETE revokes every CLI session of the user, including the one making the
+// request, for `grape logout --all`. API tokens are not affected; they are
+// revoked with `grape token revoke`.
+export async function DELETE(req: Request) {
+	const { payload, error: authError } = await verifyCliToken(req);
+	if (authError) {
+		return authError;
+	}
+	if (payload.token_id) {
+		return apiTokenForbidden();
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 }
+		);
+	}
+
+	const supabase = await createServiceRoleClient();
+	const { data, error } = await supabase
+		.from("cli_sessions")
+		.update({ revoked_at: new Date().toISOString() })
+		.eq("profile_id", userId)
+		.is("revoked_at", null)
+		.select("id");
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+	return NextResponse.json({ revoked: data.length });
+}
@@ -0,0 +1,45 @@
+import { apiTokenForbidden, verifyCliToken } from "@/lib/cli/auth";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// DELETE revokes an API token. It stays in the table, marked revoked, so
+// it keeps failing with a clear error.
+export async function DELETE(
+	req: Request,
+	{ params }: { params: Promise<{ id: string }> },
+) {
+	const { payload, error: authError } = await verifyCliToken(req);
+	if (authError) {
+		return authError;
+	}
+	if (payload.token_id) {
+		return apiTokenForbidden();
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 },
+		);
+	}
+
+	const { id } = await params;
+	const supabase = await createServiceRoleClient();
+
+	const { data, error } = await supabase
+		.from("api_tokens")
+		.update({ revoked_at: new Date().toISOString() })
+		.eq("id", id)
+		.eq("profile_id", userId)
+		.is("revoked_at", null)
+		.select("id");
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+	if (!data?.length) {
+		return NextResponse.json({ error: "Token not found" }, { status: 404 });
+	}
+	return NextResponse.json({ revoked: true });
+}
@@ -0,0 +1,141 @@
+import { apiTokenForbidden, verifyCliToken } from "@/lib/cli/auth";
+import {
+	API_TOKEN_SCOPES,
+	MAX_TOKEN_LIFETIME_DAYS,
+	displayPrefix,
+	generateApiToken,
+	hashApiToken,
+	isApiTokenScope,
+} from "@/lib/cli/tokens";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// Columns returned for a token; the hash never leaves the database.
+const TOKEN_COLUMNS =
+	"id, name, token_prefix, scopes, expires_at, last_used_at, created_at";
+
+// GET lists the user's API tokens that have not been revoked.
+export async function GET(req: Request) {
+	const { payload, error: authError } = await verifyCliToken(req);
+	if (authError) {
+		return authError;
+	}
+	if (payload.token_id) {
+		return apiTokenForbidden();
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 }
+		);
+	}
+
+	const supabase = await createServiceRoleClient();
+	const { data, error } = await supabase
+		.from("api_tokens")
+		.select(TOKEN_COLUMNS)
+		.eq("profile_id", userId)
+		.is("revoked_at", null)
+		.order("created_at", { ascending: false });
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+	return NextResponse.json({ tokens: data });
+}
+
+// POST creates an API token with a name, scopes and an expires_at at most
+// MAX_TOKEN_LIFETIME_DAYS away. The token itself is only returned here.
+export async function POST(req: Request) {
+	const { payload, error: authError } = await verifyCliToken(req);
+	if (authError) {
+		return authError;
+	}
+	if (payload.token_id) {
+		return apiTokenForbidden();
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 }
+		);
+	}
+
+	const { name, scopes, expires_at } = await req.json();
+	if (typeof name !== "string" || !name.trim() || name.length > 100) {
+		return NextResponse.json(
+			{ error: "name must be between 1 and 100 characters" },
+			{ status: 400 }
+		);
+	}
+	if (
+		!Array.isArray(scopes) ||
+		scopes.length === 0 ||
+		!scopes.every(isApiTokenScope)
+	) {
+		return NextResponse.json(
+			{ error: `scopes must be one or more of ${API_TOKEN_SCOPES.join(", ")}` },
+			{ status: 400 }
+		);
+	}
+	const expiresAt = new Date(expires_at);
+	const maxExpiry = Date.now() + MAX_TOKEN_LIFETIME_DAYS * 24 * 60 * 60 * 1000;
+	if (
+		Number.isNaN(expiresAt.getTime()) ||
+		expiresAt.getTime() <= Date.now() ||
+		expiresAt.getTime() > maxExpiry
+	) {
+		return NextResponse.json(
+			{
+				error: `expires_at must be in the future and within ${MAX_TOKEN_LIFETIME_DAYS} days`,
+			},
+			{ status: 400 }
+		);
+	}
+
+	const supabase = await createServiceRoleClient();
+
+	const { data: existing } = await supabase
+		.from("api_tokens")
+		.select("id")
+		.eq("profile_id", userId)
+		.eq("name", name.trim())
+		.is("revoked_at", null)
+		.maybeSingle();
+	if (existing) {
+		return NextResponse.json(
+			{ error: `A token named "${name.trim()}" already exists` },
+			{ status: 409 }
+		);
+	}
+
+	const token = generateApiToken();
+	const { data, error } = await supabase
+		.from("api_tokens")
+		.insert({
+			profile_id: userId,
+			name: name.trim(),
+			token_hash: hashApiToken(token),
+			token_prefix: displayPrefix(token),
+			scopes: [...new Set(scopes)],
+			expires_at: expiresAt.toISOString(),
+		})
+		.select(TOKEN_COLUMNS)
+		.single();
+
+	if (error) {
+		console.error("Error creating API token:", error);
+		return NextResponse.json(
+			{ error: "Failed to create token" },
+			{ status: 500 }
+		);
+	}
+	return NextResponse.json(
+		{ token: data, secret: token },
+		{ status: 201, headers: { "Cache-Control": "no-store" } }
+	);
+}
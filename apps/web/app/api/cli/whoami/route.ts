@@ -0,0 +1,56 @@
+import { verifyCliToken } from "@/lib/cli/auth";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import { NextResponse } from "next/server";
+
+// GET describes the token of the request, for `grape auth status`: the user
+// it belongs to and, for API tokens, their name, scopes and expiry.
+export async function GET(req: Request) {
+	const { payload, error: authError } = await verifyCliToken(req);
+	if (authError) {
+		return authError;
+	}
+
+	const userId = payload.sub;
+	if (!userId) {
+		return NextResponse.json(
+			{ error: "Invalid token payload" },
+			{ status: 400 }
+		);
+	}
+
+	const supabase = await createServiceRoleClient();
+	const { data: profile } = await supabase
+		.from("profiles")
+		.select("email")
+		.eq("id", userId)
+		.maybeSingle();
+
+	if (!payload.token_id) {
+		return NextResponse.json({
+			user_id: userId,
+			email: profile?.email ?? payload.email ?? null,
+			token_type: "login",
+			expires_at: payload.exp
+				? new Date(payload.exp * 1000).toISOString()
+				: null,
+		});
+	}
+
+	const { data: token, error } = await supabase
+		.from("api_tokens")
+		.select("name, scopes, expires_at")
+		.eq("id", payload.token_id)
+		.single();
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+	return NextResponse.json({
+		user_id: userId,
+		email: profile?.email ?? null,
+		token_type: "api",
+		token_name: token.name,
+		scopes: token.scopes,
+		expires_at: token.expires_at,
+	});
+}
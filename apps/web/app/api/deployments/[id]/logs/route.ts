@@ -1,6 +1,16 @@
+import { verifyCliToken } from "@/lib/cli/auth";
 import { createClient } from "@/lib/supabase/server";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import type { Database } from "@/types/database.types";
 import { NextResponse } from "next/server";
 
+type LogEntry = {
+	message: string;
+	level: Database["public"]["Enums"]["logs_level"];
+	step?: string | null;
+	created_at?: string | null;
+};
+
 export async function GET(
 	req: Request,
 	{ params }: { params: { id: string } }
@@ -40,34 +50,74 @@ export async function GET(
 	}
 }
 
+// POST appends log entries to a deployment, for `grape deploy` and the
+// agent. It takes the CLI's bearer token, so the deployment must belong to
+// its user.
 export async function POST(
 	req: Request,
-	{ params }: { params: { id: string } }
+	{ params }: { params: Promise<{ id: string }> }
 ) {
 	try {
-		const supabase = await createClient();
-		const {
-			data: { session },
-		} = await supabase.auth.getSession();
+		const { payload, error: authError } = await verifyCliToken(
+			req,
+			"configs:write"
+		);
+		if (authError) {
+			return authError;
+		}
 
-		if (!session) {
-			return NextResponse.json({ error: "Unauthorized" }, { status: 401 });
+		const userId = payload.sub;
+		if (!userId) {
+			return NextResponse.json(
+				{ error: "Invalid token payload" },
+				{ status: 400 }
+			);
 		}
 
-		const { message, level, step } = await req.json();
-		if (!message || !level) {
+		const { id } = await params;
+		const supabase = await createServiceRoleClient();
+		const { data: deployment, error: fetchError } = await supabase
+			.from("deployments")
+			.select("id")
+			.eq("id", id)
+			.eq("profile_id", userId)
+			.maybeSingle();
+
+		if (fetchError) {
+			return NextResponse.json(
+				{ error: `Failed to fetch deployment: ${fetchError.message}` },
+				{ status: 500 }
+			);
+		}
+		if (!deployment) {
+			return NextResponse.json(
+				{ error: "Deployment not found" },
+				{ status: 404 }
+			);
+		}
+
+		// Accept a single entry or a batch of entries shipped by the CLI/agent.
+		const body = await req.json();
+		const entries: LogEntry[] = Array.isArray(body) ? body : [body];
+		if (
+			entries.length === 0 ||
+			entries.some((entry) => !entry?.message || !entry?.level)
+		) {
 			return NextResponse.json(
 				{ error: "Message and level are required." },
 				{ status: 400 }
 			);
 		}
 
-		const { data, error } = await supabase.from("deployment_logs").insert({
-			deployment_id: params.id,
-			message,
-			level,
-			step,
-		});
+		const { data, error } = await supabase.from("deployment_logs").insert(
+			entries.map(({ message, level, step, created_at }) => ({
+				deployment_id: id,
+				message,
+				level,
+				step,
+				created_at,
+			}))
+		);
 
 		if (error) {
 			return NextResponse.json(
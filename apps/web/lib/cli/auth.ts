@@ -1,6 +1,35 @@
+import {
+	type ApiTokenScope,
+	hasScope,
+	hashApiToken,
+	isApiToken,
+} from "@/lib/cli/tokens";
+import {
+	cliKeys,
+	cliTokenAudience,
+	isSessionActive,
+	verifyCliJwt,
+} from "@/lib/cli/sessions";
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
 import * as jose from "jose";
 
-export async function verifyCliToken(req: Request) {
+// CliTokenPayload identifies the caller. token_id and scopes are only set
+// for API tokens; access tokens from `grape login` may do everything.
+export type CliTokenPayload = jose.JWTPayload & {
+	token_id?: string;
+	scopes?: string[];
+};
+
+type VerifyResult =
+	| { payload: CliTokenPayload; error: null }
+	| { payload: null; error: Response };
+
+// verifyCliToken checks the bearer token of a CLI request: an access token
+// from `grape login`, or an API token that must grant scope.
+export async function verifyCliToken(
+	req: Request,
+	scope: ApiTokenScope = "configs:read"
+): Promise<VerifyResult> {
 	const authHeader = req.headers.get("Authorization");
 	if (!authHeader || !authHeader.startsWith("Bearer ")) {
 		return {
@@ -13,9 +42,12 @@ export async function verifyCliToken(req: Request) {
 	}
 
 	const token = authHeader.substring(7);
-	const jwtSecret = process.env.CLI_JWT_SECRET;
-	if (!jwtSecret) {
-		console.error("CLI_JWT_SECRET is not set.");
+	if (isApiToken(token)) {
+		return verifyApiToken(token, scope);
+	}
+
+	const keys = await cliKeys();
+	if (!keys) {
 		return {
 			error: new Response(
 				JSON.stringify({
@@ -28,16 +60,22 @@ export async function verifyCliToken(req: Request) {
 	}
 
 	try {
-		const secret = new TextEncoder().encode(jwtSecret);
-		const { payload } = await jose.jwtVerify(token, secret, {
-			issuer: "urn:example:issuer",
-			audience: "urn:example:audience",
-		});
+		const payload = await verifyCliJwt(
+			token,
+			"access",
+			keys,
+			cliTokenAudience(req)
+		);
 
-		if (payload.type !== "access") {
+		// Tokens issued before sessions existed have no sid; they expire
+		// within the hour.
+		if (
+			payload.sid !== undefined &&
+			!(await isSessionActive(payload.sid, payload.sub))
+		) {
 			return {
 				error: new Response(
-					JSON.stringify({ error: "Unauthorized: Invalid token type" }),
+					JSON.stringify({ error: "Unauthorized: Session revoked" }),
 					{ status: 401 }
 				),
 				payload: null,
@@ -46,12 +84,90 @@ export async function verifyCliToken(req: Request) {
 
 		return { payload, error: null };
 	} catch (err) {
+		const message =
+			err instanceof jose.errors.JWTExpired
+				? "Unauthorized: Token expired"
+				: "Unauthorized: Invalid token";
 		return {
 			error: new Response(
-				JSON.stringify({ error: "Unauthorized: Invalid token" }),
+				JSON.stringify({ error: message }),
 				{ status: 401 }
 			),
 			payload: null,
 		};
 	}
 }
+
+// apiTokenForbidden answers token management requests made with an API
+// token, so a leaked one cannot mint new ones.
+export function apiTokenForbidden() {
+	return new Response(
+		JSON.stringify({
+			error: "API tokens cannot manage tokens or sessions. Run `grape login` first.",
+		}),
+		{ status: 403 }
+	);
+}
+
+// verifyApiToken looks up an API token by its hash.
+async function verifyApiToken(
+	token: string,
+	scope: ApiTokenScope
+): Promise<VerifyResult> {
+	const supabase = await createServiceRoleClient();
+	const { data: row, error } = await supabase
+		.from("api_tokens")
+		.select("id, profile_id, scopes, expires_at, revoked_at")
+		.eq("token_hash", hashApiToken(token))
+		.maybeSingle();
+
+	if (error) {
+		console.error("Error loading API token:", error);
+		return {
+			error: new Response(
+				JSON.stringify({ error: "Internal server error" }),
+				{ status: 500 }
+			),
+			payload: null,
+		};
+	}
+	if (
+		!row ||
+		row.revoked_at ||
+		(row.expires_at && new Date(row.expires_at) < new Date())
+	) {
+		return {
+			error: new Response(
+				JSON.stringify({
+					error: "Unauthorized: Invalid, expired or revoked API token",
+				}),
+				{ status: 401 }
+			),
+			payload: null,
+		};
+	}
+	if (!hasScope(row.scopes, scope)) {
+		return {
+			error: new Response(
+				JSON.stringify({ error: `Forbidden: The API token lacks the ${scope} scope` }),
+				{ status: 403 }
+			),
+			payload: null,
+		};
+	}
+
+	await supabase
+		.from("api_tokens")
+		.update({ last_used_at: new Date().toISOString() })
+		.eq("id", row.id);
+
+	return {
+		payload: {
+			sub: row.profile_id,
+			type: "access",
+			token_id: row.id,
+			scopes: row.scopes,
+		},
+		error: null,
+	};
+}
@@ -0,0 +1,88 @@
+import type { Database, TablesInsert } from "@/types/database.types";
+import type { SupabaseClient } from "@supabase/supabase-js";
+import { NextResponse } from "next/server";
+
+type ConfigurationInsert = TablesInsert<"configurations">;
+
+// Columns the CLI may set. Ownership, ids, timestamps and download counters
+// are maintained by the server.
+const WRITABLE_FIELDS = [
+	"aws_account_id",
+	"aws_region",
+	"container_platform",
+	"create_vpc",
+	"db_max_capacity",
+	"db_min_capacity",
+	"description",
+	"dns_domain_name",
+	"dns_hosted_zone",
+	"eks_cluster_admins",
+	"enable_cloudfront_waf",
+	"enable_dns",
+	"enable_gitops_destination",
+	"enable_karpenter",
+	"enable_redis",
+	"environment_repository",
+	"environment_stage",
+	"full_config",
+	"gitops_app_template",
+	"gitops_app_token",
+	"gitops_argocd_token",
+	"gitops_destinations_repo",
+	"gitops_repository",
+	"project_name",
+	"redis_allowed_cidr_blocks",
+	"ses_queues_topics",
+	"status",
+	"terraform_version",
+	"vpc_cidr",
+] as const satisfies readonly (keyof ConfigurationInsert)[];
+
+export function pickWritableFields(
+	body: Record<string, unknown>
+): Partial<ConfigurationInsert> {
+	const picked: Record<string, unknown> = {};
+	for (const field of WRITABLE_FIELDS) {
+		if (field in body) {
+			picked[field] = body[field];
+		}
+	}
+	return picked as Partial<ConfigurationInsert>;
+}
+
+// Deployment states during which a configuration must not be deleted or
+// renamed, since the running job still refers to it.
+export const ACTIVE_DEPLOYMENT_STATUSES = [
+	"pending",
+	"initializing",
+	"planning",
+	"applying",
+	"destroying",
+] as const satisfies readonly Database["public"]["Enums"]["deployment_status"][];
+
+// activeDeploymentConflict returns a 409 response when the configuration has
+// deployments in progress, or a 500 response when they cannot be looked up.
+export async function activeDeploymentConflict(
+	supabase: SupabaseClient<Database>,
+	configurationId: string,
+	projectName: string
+): Promise<NextResponse | null> {
+	const { count, error } = await supabase
+		.from("deployments")
+		.select("id", { count: "exact", head: true })
+		.eq("configuration_id", configurationId)
+		.in("status", [...ACTIVE_DEPLOYMENT_STATUSES]);
+
+	if (error) {
+		return NextResponse.json({ error: error.message }, { status: 500 });
+	}
+	if (count) {
+		return NextResponse.json(
+			{
+				error: `Configuration ${projectName} has ${count} active deployment${count === 1 ? "" : "s"}; wait for ${count === 1 ? "it" : "them"} to finish`,
+			},
+			{ status: 409 }
+		);
+	}
+	return null;
+}
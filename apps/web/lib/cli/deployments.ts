@@ -0,0 +1,58 @@
+import type { Database, Tables } from "@/types/database.types";
+
+type DeploymentStatus = Database["public"]["Enums"]["deployment_status"];
+
+// The agent speaks the statuses of the architecture spec; the deployments
+// table has its own. AgentStatus is what `grape agent run` sends and
+// receives.
+export type AgentStatus = "QUEUED" | "PROCESSING" | "SUCCESS" | "FAILED";
+
+const AGENT_STATUSES: Record<DeploymentStatus, AgentStatus> = {
+	pending: "QUEUED",
+	initializing: "PROCESSING",
+	planning: "PROCESSING",
+	applying: "PROCESSING",
+	destroying: "PROCESSING",
+	completed: "SUCCESS",
+	failed: "FAILED",
+	cancelled: "FAILED",
+};
+
+// Deployment states an agent has claimed and may report on.
+export const CLAIMED_DEPLOYMENT_STATUSES = [
+	"initializing",
+	"planning",
+	"applying",
+	"destroying",
+] as const satisfies readonly DeploymentStatus[];
+
+// finishedStatus maps the final status reported by an agent to the table's.
+export function finishedStatus(status: unknown): DeploymentStatus | null {
+	switch (status) {
+		case "SUCCESS":
+			return "completed";
+		case "FAILED":
+			return "failed";
+		default:
+			return null;
+	}
+}
+
+type ClaimedDeployment = Tables<"deployments"> & {
+	configurations: Tables<"configurations"> | null;
+};
+
+// agentDeployment returns a claimed deployment as the agent expects it, with
+// its configuration as config_snapshot.
+export function agentDeployment(deployment: ClaimedDeployment) {
+	const { configurations, ...row } = deployment;
+	return {
+		id: row.id,
+		configuration_id: row.configuration_id,
+		name: row.name,
+		status: AGENT_STATUSES[row.status],
+		config_snapshot: configurations,
+		error_message: row.error_message,
+		created_at: row.created_at,
+	};
+}
@@ -0,0 +1,112 @@
+import { NextResponse } from "next/server";
+
+// OAuth 2.0 Device Authorization Grant (RFC 8628) for `grape login`.
+//
+// The CLI requests a device code and a user code from /api/auth/cli/device,
+// the user enters the user code at /cli/login and approves it, and the CLI
+// polls /api/auth/cli/exchange with the device code until it gets tokens.
+// Pending logins are rows of the cli_logins table.
+//
+// Approving also shows a short verification code in the browser, which the
+// user types into the CLI. The exchange only succeeds with it, so knowing
+// the device code alone is not enough to take over a login.
+
+export const DEVICE_CODE_GRANT_TYPE =
+	"urn:ietf:params:oauth:grant-type:device_code";
+
+// How long a code can be used, and the minimum wait between polls.
+export const DEVICE_CODE_TTL_SECONDS = 15 * 60;
+export const DEFAULT_POLL_INTERVAL = 5;
+// Added to the interval each time the CLI polls too fast (RFC 8628 §3.5).
+export const SLOW_DOWN_INCREMENT = 5;
+
+// Consonants only, so codes cannot spell words, and without letters that
+// look alike (RFC 8628 §6.1).
+const USER_CODE_ALPHABET = "BCDFGHJKLMNPQRSTVWXZ";
+const USER_CODE_LENGTH = 8;
+
+// Wrong verification codes allowed before the login is cancelled.
+export const MAX_VERIFICATION_ATTEMPTS = 5;
+
+const VERIFICATION_DIGITS = "0123456789";
+
+function randomChars(alphabet: string, length: number): string {
+	const bytes = crypto.getRandomValues(new Uint8Array(length));
+	let out = "";
+	for (const b of bytes) {
+		// 256 is not a multiple of the alphabet size; the bias is negligible
+		// for codes that expire in minutes.
+		out += alphabet[b % alphabet.length];
+	}
+	return out;
+}
+
+export function generateDeviceCode(): string {
+	const bytes = crypto.getRandomValues(new Uint8Array(32));
+	return Buffer.from(bytes).toString("base64url");
+}
+
+// generateUserCode returns a code like "WDJB-MJHT".
+export function generateUserCode(): string {
+	const code = randomChars(USER_CODE_ALPHABET, USER_CODE_LENGTH);
+	return `${code.slice(0, 4)}-${code.slice(4)}`;
+}
+
+// generateVerificationCode returns a code like "KX-47".
+export function generateVerificationCode(): string {
+	return `${randomChars(USER_CODE_ALPHABET, 2)}-${randomChars(VERIFICATION_DIGITS, 2)}`;
+}
+
+// normalizeVerificationCode accepts "kx47", "KX-47" or " kx 47 ".
+export function normalizeVerificationCode(input: unknown): string | null {
+	if (typeof input !== "string") {
+		return null;
+	}
+	const code = input.toUpperCase().replace(/[^A-Z0-9]/g, "");
+	if (!/^[A-Z]{2}[0-9]{2}$/.test(code)) {
+		return null;
+	}
+	return `${code.slice(0, 2)}-${code.slice(2)}`;
+}
+
+// normalizeUserCode accepts the code as typed: in any case, with or without
+// the dash and surrounding spaces. It returns null for malformed codes.
+export function normalizeUserCode(input: unknown): string | null {
+	if (typeof input !== "string") {
+		return null;
+	}
+	const code = input.toUpperCase().replace(/[^A-Z]/g, "");
+	if (code.length !== USER_CODE_LENGTH) {
+		return null;
+	}
+	for (const c of code) {
+		if (!USER_CODE_ALPHABET.includes(c)) {
+			return null;
+		}
+	}
+	return `${code.slice(0, 4)}-${code.slice(4)}`;
+}
+
+// deviceError answers the token endpoint with an RFC 6749 §5.2 error. The
+// verification errors extend the standard set.
+export function deviceError(
+	error:
+		| "authorization_pending"
+		| "slow_down"
+		| "expired_token"
+		| "access_denied"
+		| "invalid_grant"
+		| "invalid_request"
+		| "unsupported_grant_type"
+		| "verification_required"
+		| "invalid_verification_code"
+		| "too_many_attempts",
+	description: string,
+	status = 400,
+	extra: Record<string, unknown> = {}
+) {
+	return NextResponse.json(
+		{ error, error_description: description, ...extra },
+		{ status, headers: { "Cache-Control": "no-store" } }
+	);
+}
@@ -0,0 +1,181 @@
+import { createServiceRoleClient } from "@/lib/supabase/service-role-client";
+import * as jose from "jose";
+
+// Every `grape login` starts a session, a row of cli_sessions. The access
+// and refresh tokens carry its id in the sid claim, so `grape logout` can
+// revoke them on the server; revoked sessions can neither call the API nor
+// refresh.
+
+// Tokens are signed with ES256 when CLI_JWT_PRIVATE_KEY (a PKCS #8 PEM) is
+// set, and the CLI verifies them with the public key published at
+// /api/auth/cli/jwks. Without it they are signed with HS256 and
+// CLI_JWT_SECRET, which only the portal can verify. HS256 tokens keep being
+// accepted while CLI_JWT_SECRET is set, so switching does not log anyone out.
+
+// Tokens issued before the issuer became the portal URL carry these. The
+// portal accepts them until they expire; the CLI does not.
+const LEGACY_JWT_ISSUER = "urn:example:issuer";
+const LEGACY_JWT_AUDIENCE = "urn:example:audience";
+
+export type CliTokenAudience = {
+	issuer: string;
+	audience: string;
+};
+
+// cliTokenAudience returns the issuer and audience of CLI tokens. The issuer
+// is the portal URL the request was sent to, or CLI_JWT_ISSUER when the
+// portal is reached under several URLs, so the CLI can tell which portal a
+// token belongs to.
+export function cliTokenAudience(req: Request): CliTokenAudience {
+	const issuer = process.env.CLI_JWT_ISSUER || new URL(req.url).origin;
+	return { issuer, audience: `${issuer}/api/cli` };
+}
+
+export const ACCESS_TOKEN_TTL_SECONDS = 60 * 60;
+const REFRESH_TOKEN_TTL = "90d";
+
+export type CliTokenType = "access" | "refresh";
+
+export type CliTokenClaims = {
+	sub: string;
+	email?: string | null;
+	sid: string;
+};
+
+export type CliKeys = {
+	privateKey: CryptoKey | null;
+	publicKey: CryptoKey | null;
+	// publicJwk is the public key as published, with its kid.
+	publicJwk: jose.JWK | null;
+	secret: Uint8Array | null;
+};
+
+let loadedKeys: Promise<CliKeys | null> | undefined;
+
+// cliKeys returns the keys CLI tokens are signed and verified with, or null
+// when neither CLI_JWT_PRIVATE_KEY nor CLI_JWT_SECRET is set.
+export function cliKeys(): Promise<CliKeys | null> {
+	loadedKeys ??= loadCliKeys().catch((error) => {
+		console.error("Error loading CLI_JWT_PRIVATE_KEY:", error);
+		return null;
+	});
+	return loadedKeys;
+}
+
+async function loadCliKeys(): Promise<CliKeys | null> {
+	const jwtSecret = process.env.CLI_JWT_SECRET;
+	const secret = jwtSecret ? new TextEncoder().encode(jwtSecret) : null;
+
+	const pem = process.env.CLI_JWT_PRIVATE_KEY;
+	if (!pem) {
+		if (!secret) {
+			console.error("Neither CLI_JWT_PRIVATE_KEY nor CLI_JWT_SECRET is set.");
+			return null;
+		}
+		return { privateKey: null, publicKey: null, publicJwk: null, secret };
+	}
+
+	// Environment files often hold the PEM on one line with escaped newlines.
+	const privateKey = await jose.importPKCS8(
+		pem.replace(/\\n/g, "\n"),
+		"ES256",
+		{ extractable: true }
+	);
+	// eslint-disable-next-line @typescript-eslint/no-unused-vars
+	const { d, ...jwk } = await jose.exportJWK(privateKey);
+	const publicJwk: jose.JWK = {
+		...jwk,
+		kid: await jose.calculateJwkThumbprint(jwk),
+		alg: "ES256",
+		use: "sig",
+	};
+	const publicKey = (await jose.importJWK(publicJwk, "ES256")) as CryptoKey;
+	return { privateKey, publicKey, publicJwk, secret };
+}
+
+export async function signCliToken(
+	type: CliTokenType,
+	claims: CliTokenClaims,
+	keys: CliKeys,
+	{ issuer, audience }: CliTokenAudience
+): Promise<string> {
+	const jwt = new jose.SignJWT({ ...claims, type })
+		.setIssuedAt()
+		.setIssuer(issuer)
+		.setAudience(audience)
+		.setExpirationTime(
+			type === "access" ? `${ACCESS_TOKEN_TTL_SECONDS}s` : REFRESH_TOKEN_TTL
+		);
+
+	if (keys.privateKey && keys.publicJwk) {
+		return jwt
+			.setProtectedHeader({ alg: "ES256", kid: keys.publicJwk.kid })
+			.sign(keys.privateKey);
+	}
+	return jwt.setProtectedHeader({ alg: "HS256" }).sign(keys.secret!);
+}
+
+// verifyCliJwt checks the signature, issuer, audience, expiry and type of a
+// CLI token. It throws when any of them is wrong.
+export async function verifyCliJwt(
+	token: string,
+	type: CliTokenType,
+	keys: CliKeys,
+	{ issuer, audience }: CliTokenAudience
+): Promise<jose.JWTPayload> {
+	const algorithms = [
+		...(keys.publicKey ? ["ES256"] : []),
+		...(keys.secret ? ["HS256"] : []),
+	];
+	const { payload } = await jose.jwtVerify(
+		token,
+		(header) => {
+			const key = header.alg === "ES256" ? keys.publicKey : keys.secret;
+			if (!key) {
+				throw new Error(`no key for ${header.alg}`);
+			}
+			return key;
+		},
+		{
+			issuer: [issuer, LEGACY_JWT_ISSUER],
+			audience: [audience, LEGACY_JWT_AUDIENCE],
+			algorithms,
+		}
+	);
+	if (payload.type !== type) {
+		throw new Error(`expected a ${type} token`);
+	}
+	return payload;
+}
+
+export async function createCliSession(profileId: string): Promise<string> {
+	const supabase = await createServiceRoleClient();
+	const { data, error } = await supabase
+		.from("cli_sessions")
+		.insert({ profile_id: profileId })
+		.select("id")
+		.single();
+	if (error) {
+		throw error;
+	}
+	return data.id;
+}
+
+// isSessionActive reports whether the session exists for the user and has
+// not been revoked.
+export async function isSessionActive(
+	sid: unknown,
+	profileId: string | undefined
+): Promise<boolean> {
+	if (typeof sid !== "string" || !profileId) {
+		return false;
+	}
+	const supabase = await createServiceRoleClient();
+	const { data } = await supabase
+		.from("cli_sessions")
+		.select("revoked_at")
+		.eq("id", sid)
+		.eq("profile_id", profileId)
+		.maybeSingle();
+	return !!data && !data.revoked_at;
+}
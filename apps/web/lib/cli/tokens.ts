@@ -0,0 +1,56 @@
+import { createHash } from "crypto";
+
+// Long-lived API tokens for CI, created with `grape token create`. They are
+// opaque strings, not JWTs: only a SHA-256 hash is stored, so a token can be
+// shown once and revoked later. verifyCliToken accepts them wherever a CLI
+// access token is accepted, limited to their scopes.
+
+export const API_TOKEN_PREFIX = "grape_";
+
+// Characters of the token kept in plain text, so users can tell tokens
+// apart in `grape token list`.
+const DISPLAY_PREFIX_LENGTH = API_TOKEN_PREFIX.length + 6;
+
+// Tokens cannot be valid for longer than this.
+export const MAX_TOKEN_LIFETIME_DAYS = 365;
+
+export const API_TOKEN_SCOPES = ["configs:read", "configs:write"] as const;
+
+export type ApiTokenScope = (typeof API_TOKEN_SCOPES)[number];
+
+// Scopes that include another one, e.g. writing configurations requires
+// reading them.
+const IMPLIED_SCOPES: Record<ApiTokenScope, ApiTokenScope[]> = {
+	"configs:read": [],
+	"configs:write": ["configs:read"],
+};
+
+export function isApiToken(token: string): boolean {
+	return token.startsWith(API_TOKEN_PREFIX);
+}
+
+export function isApiTokenScope(scope: unknown): scope is ApiTokenScope {
+	return (API_TOKEN_SCOPES as readonly unknown[]).includes(scope);
+}
+
+// hasScope reports whether scopes grant scope, directly or implied.
+export function hasScope(scopes: string[], scope: ApiTokenScope): boolean {
+	return scopes.some(
+		(s) =>
+			s === scope ||
+			(isApiTokenScope(s) && IMPLIED_SCOPES[s].includes(scope))
+	);
+}
+
+export function generateApiToken(): string {
+	const bytes = crypto.getRandomValues(new Uint8Array(32));
+	return API_TOKEN_PREFIX + Buffer.from(bytes).toString("base64url");
+}
+
+export function hashApiToken(token: string): string {
+	return createHash("sha256").update(token).digest("hex");
+}
+
+export function displayPrefix(token: string): string {
+	return token.slice(0, DISPLAY_PREFIX_LENGTH);
+}
@@ -14,29 +14,94 @@ export type Database = {
   }
   public: {
     Tables: {
+      api_tokens: {
+        Row: {
+          created_at: string
+          expires_at: string | null
+          id: string
+          last_used_at: string | null
+          name: string
+          profile_id: string
+          revoked_at: string | null
+          scopes: string[]
+          token_hash: string
+          token_prefix: string
+        }
+        Insert: {
+          created_at?: string
+          expires_at?: string | null
+          id?: string
+          last_used_at?: string | null
+          name: string
+          profile_id: string
+          revoked_at?: string | null
+          scopes: string[]
+          token_hash: string
+          token_prefix: string
+        }
+        Update: {
+          created_at?: string
+          expires_at?: string | null
+          id?: string
+          last_used_at?: string | null
+          name?: string
+          profile_id?: string
+          revoked_at?: string | null
+          scopes?: string[]
+          token_hash?: string
+          token_prefix?: string
+        }
+        Relationships: [
+          {
+            foreignKeyName: "api_tokens_profile_id_fkey"
+            columns: ["profile_id"]
+            isOneToOne: false
+            referencedRelation: "profiles"
+            referencedColumns: ["id"]
+          },
+        ]
+      }
       cli_logins: {
         Row: {
           created_at: string | null
+          denied_at: string | null
           device_code: string
           expires_at: string | null
+          interval: number | null
+          last_polled_at: string | null
           profile_id: string | null
+          provider_token: string | null
           refresh_token: string | null
+          user_code: string | null
+          verification_attempts: number | null
           verification_code: string | null
         }
         Insert: {
           created_at?: string | null
+          denied_at?: string | null
           device_code: string
           expires_at?: string | null
+          interval?: number | null
+          last_polled_at?: string | null
           profile_id?: string | null
+          provider_token?: string | null
           refresh_token?: string | null
+          user_code?: string | null
+          verification_attempts?: number | null
           verification_code?: string | null
         }
         Update: {
           created_at?: string | null
+          denied_at?: string | null
           device_code?: string
           expires_at?: string | null
+          interval?: number | null
+          last_polled_at?: string | null
           profile_id?: string | null
+          provider_token?: string | null
           refresh_token?: string | null
+          user_code?: string | null
+          verification_attempts?: number | null
           verification_code?: string | null
         }
         Relationships: [
@@ -49,6 +114,38 @@ export type Database = {
           },
         ]
       }
+      cli_sessions: {
+        Row: {
+          created_at: string
+          id: string
+          profile_id: string
+          refreshed_at: string | null
+          revoked_at: string | null
+        }
+        Insert: {
+          created_at?: string
+          id?: string
+          profile_id: string
+          refreshed_at?: string | null
+          revoked_at?: string | null
+        }
+        Update: {
+          created_at?: string
+          id?: string
+          profile_id?: string
+          refreshed_at?: string | null
+          revoked_at?: string | null
+        }
+        Relationships: [
+          {
+            foreignKeyName: "cli_sessions_profile_id_fkey"
+            columns: ["profile_id"]
+            isOneToOne: false
+            referencedRelation: "profiles"
+            referencedColumns: ["id"]
+          },
+        ]
+      }
       cloud_identities: {
         Row: {
           created_at: string | null